@@ -144,6 +144,21 @@ func (c *Context) SeleniumSessions() SeleniumSessions {
 	return *result
 }
 
+//Recorder returns workflow recorder or nil if recording/replay is not enabled.
+func (c *Context) Recorder() *Recorder {
+	var result *Recorder
+	if !c.Contains(recorderKey) {
+		return nil
+	}
+	c.GetInto(recorderKey, &result)
+	return result
+}
+
+//SetRecorder sets recorder to capture or replay exec commands, http trips, rest calls and transfers.
+func (c *Context) SetRecorder(recorder *Recorder) {
+	_ = c.Put(recorderKey, recorder)
+}
+
 //Service returns a service fo provided id or error.
 func (c *Context) Service(name string) (Service, error) {
 	manager, err := c.Manager()
@@ -153,7 +168,7 @@ func (c *Context) Service(name string) (Service, error) {
 	return manager.Service(name)
 }
 
-//Deffer add function to be executed if context closes. If returns currently registered functions.
+//Deffer add function to be executed if context closes, functions are executed in reverse order like go defer, so that resources registered later are released while terminal sessions they use are still open. If returns currently registered functions.
 func (c *Context) Deffer(functions ...func()) []func() {
 	var result *[]func()
 	if !c.Contains(deferFunctionsKey) {
@@ -326,21 +341,21 @@ func (c *Context) AsRequest(serviceName, action string, source map[string]interf
 	return request, err
 }
 
-//Close closes this context, it executes all deferred function and set closed flag.
+//Close closes this context, it executes all deferred function in reverse order of registration and set closed flag.
 func (c *Context) Close() {
 	atomic.StoreInt32(&c.closed, 1)
 	for _, context := range c.cloned {
 		context.Close()
 	}
-	for _, function := range c.Deffer() {
-		function()
+	var functions = c.Deffer()
+	for i := len(functions) - 1; i >= 0; i-- {
+		functions[i]()
 	}
 }
 
 //MakeAsyncSafe makes this contex async safe
 func (c *Context) MakeAsyncSafe() {
-	c.Context.Remove(seleniumSessionsKey)
-	c.Context.Remove(systemTerminalSessionsKey)
+	c.MakeSessionsAsyncSafe()
 	c.Events = &Events{
 		mutex:  &sync.Mutex{},
 		Events: make([]*Event, 0),
@@ -348,6 +363,12 @@ func (c *Context) MakeAsyncSafe() {
 	c.EventLogger = nil
 }
 
+//MakeSessionsAsyncSafe makes this context terminal and selenium sessions async safe, events are still shared with the parent context
+func (c *Context) MakeSessionsAsyncSafe() {
+	c.Context.Remove(seleniumSessionsKey)
+	c.Context.Remove(systemTerminalSessionsKey)
+}
+
 /*
 NewDefaultState returns a new default state.
 It comes with the following registered keys:
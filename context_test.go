@@ -86,3 +86,20 @@ func TestContext_Transfer(t *testing.T) {
 	})
 	assert.NotNil(t, err)
 }
+
+func TestContext_Close(t *testing.T) {
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	var closed = make([]string, 0)
+	context.Deffer(func() {
+		closed = append(closed, "session")
+	})
+	context.Deffer(func() {
+		closed = append(closed, "compose")
+	}, func() {
+		closed = append(closed, "logs")
+	})
+	context.Close()
+	assert.True(t, context.IsClosed())
+	assert.EqualValues(t, []string{"logs", "compose", "session"}, closed)
+}
@@ -82,6 +82,11 @@ func (l *EventLogger) Log(event *Event) error {
 	return err
 }
 
+//Directory returns event logger directory
+func (l *EventLogger) Directory() string {
+	return l.directory
+}
+
 //NewEventLogger creates a new event logger
 func NewEventLogger(directory string) *EventLogger {
 	var activities Activities = make([]*WorkflowServiceActivity, 0)
@@ -37,6 +37,9 @@ func (s *OperatingSystem) Matches(target *OperatingSystemTarget) bool {
 	if target.System != "" && target.System != s.System {
 		return false
 	}
+	if target.Architecture != "" && target.Architecture != s.Architecture {
+		return false
+	}
 
 	if target.MinRequiredVersion == "" && target.MaxAllowedVersion == "" {
 		return true
@@ -85,6 +88,7 @@ func (p *SystemPath) EnvValue() string {
 type OperatingSystemTarget struct {
 	System             string
 	Name               string
+	Architecture       string //i.e. amd64, arm64
 	MinRequiredVersion string
 	MaxAllowedVersion  string
 }
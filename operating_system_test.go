@@ -21,6 +21,9 @@ func TestOperatingSystem_Matches(t *testing.T) {
 	assert.True(t, operatingSystem.Matches(&endly.OperatingSystemTarget{Name: "maxosx"}))
 	assert.False(t, operatingSystem.Matches(&endly.OperatingSystemTarget{Name: "ubuntu"}))
 
+	assert.True(t, operatingSystem.Matches(&endly.OperatingSystemTarget{Architecture: "x86_64"}))
+	assert.False(t, operatingSystem.Matches(&endly.OperatingSystemTarget{Architecture: "arm64"}))
+
 	assert.True(t, operatingSystem.Matches(&endly.OperatingSystemTarget{MinRequiredVersion: "16.7.0"}))
 	assert.False(t, operatingSystem.Matches(&endly.OperatingSystemTarget{MinRequiredVersion: "17.0.0"}))
 
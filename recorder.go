@@ -0,0 +1,258 @@
+package endly
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/bridge"
+	"github.com/viant/toolbox/ssh"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	//RecorderModeRecord represents a mode capturing exec commands, http trips, rest calls and transfers
+	RecorderModeRecord = "record"
+	//RecorderModeReplay represents a mode replaying previously captured exec commands, http trips, rest calls and transfers
+	RecorderModeReplay = "replay"
+)
+
+var recorderKey = (*Recorder)(nil)
+
+//Recorder represents a workflow recorder, it captures or replays exec commands, http trips, rest calls and transfers under the base directory.
+type Recorder struct {
+	Mode          string
+	BaseDirectory string
+	httpTrips     *HTTPServerTrips
+	httpCount     int
+	transferCount int
+	mutex         *sync.Mutex
+}
+
+//IsRecording returns true if recorder captures activities.
+func (r *Recorder) IsRecording() bool {
+	return r != nil && r.Mode == RecorderModeRecord
+}
+
+//IsReplaying returns true if recorder replays captured activities.
+func (r *Recorder) IsReplaying() bool {
+	return r != nil && r.Mode == RecorderModeReplay
+}
+
+//ExecDirectory returns exec commands directory for supplied session.
+func (r *Recorder) ExecDirectory(sessionName string) string {
+	return path.Join(r.BaseDirectory, "exec", strings.Replace(sessionName, ":", "_", 1))
+}
+
+//HTTPDirectory returns http trips directory.
+func (r *Recorder) HTTPDirectory() string {
+	return path.Join(r.BaseDirectory, "http")
+}
+
+//TransferURL returns recorded transfer asset URL for supplied sequence index and source path.
+func (r *Recorder) TransferURL(index int, sourcePath string) string {
+	return toolbox.FileSchema + path.Join(r.BaseDirectory, "transfer", fmt.Sprintf("%03d", index), sourcePath)
+}
+
+//NextTransferIndex returns a next transfer sequence index.
+func (r *Recorder) NextTransferIndex() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.transferCount++
+	return r.transferCount
+}
+
+//SSHService returns ssh replay service for supplied session.
+func (r *Recorder) SSHService(sessionName string) (ssh.Service, error) {
+	commands, err := ssh.NewReplayCommands(r.ExecDirectory(sessionName))
+	if err != nil {
+		return nil, err
+	}
+	if err = commands.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load recorded commands for %v, %v", sessionName, err)
+	}
+	return ssh.NewReplayService(commands.Shell(), commands.System(), commands, nil), nil
+}
+
+//Transport returns a http round tripper that either records or replays http trips.
+func (r *Recorder) Transport(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if r.IsReplaying() {
+		return &replayRoundTripper{recorder: r}
+	}
+	return &recordingRoundTripper{recorder: r, transport: transport}
+}
+
+//RouteToService sends JSON request to supplied URL and decode JSON response with recorder transport.
+func (r *Recorder) RouteToService(method, URL string, request, response interface{}) error {
+	var reader *bytes.Reader
+	switch payload := request.(type) {
+	case string:
+		reader = bytes.NewReader([]byte(payload))
+	case []byte:
+		reader = bytes.NewReader(payload)
+	default:
+		buf := new(bytes.Buffer)
+		if err := toolbox.NewJSONEncoderFactory().Create(buf).Encode(request); err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf.Bytes())
+	}
+	httpRequest, err := http.NewRequest(strings.ToUpper(method), URL, reader)
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set(ContentTypeKey, "application/json")
+	client := &http.Client{Transport: r.Transport(nil)}
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	return toolbox.NewJSONDecoderFactory().Create(httpResponse.Body).Decode(response)
+}
+
+func (r *Recorder) recordTrip(request *bridge.HttpRequest, response *bridge.HttpResponse) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var directory = r.HTTPDirectory()
+	if !toolbox.FileExists(directory) {
+		if err := os.MkdirAll(directory, 0744); err != nil {
+			return err
+		}
+	}
+	var index = r.httpCount
+	r.httpCount++
+	if err := writeJSON(path.Join(directory, fmt.Sprintf("bridge.HttpRequest-%v.json", index)), request); err != nil {
+		return err
+	}
+	return writeJSON(path.Join(directory, fmt.Sprintf("bridge.HttpResponse-%v.json", index)), response)
+}
+
+func (r *Recorder) replayTrip(request *http.Request) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.httpTrips == nil {
+		r.httpTrips = &HTTPServerTrips{
+			BaseDirectory: r.HTTPDirectory(),
+			IndexKeys:     []string{MethodKey, URLKey, BodyKey},
+		}
+		if err := r.httpTrips.Init(); err != nil {
+			return nil, err
+		}
+	}
+	key, err := buildKeyValue(r.httpTrips.IndexKeys, request)
+	if err != nil {
+		return nil, err
+	}
+	responses, ok := r.httpTrips.Trips[key]
+	if !ok || responses.Index >= len(responses.Responses) {
+		return nil, fmt.Errorf("failed to lookup recorded trip: %v %v", request.Method, request.URL)
+	}
+	response := responses.Responses[responses.Index]
+	responses.Index++
+	body, err := FromPayload(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var header = make(http.Header)
+	copyHeaders(response.Header, header)
+	return &http.Response{
+		StatusCode:    response.Code,
+		Status:        fmt.Sprintf("%v %v", response.Code, http.StatusText(response.Code)),
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+type recordingRoundTripper struct {
+	recorder  *Recorder
+	transport http.RoundTripper
+}
+
+func (t *recordingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	var err error
+	if request.Body != nil {
+		if requestBody, err = ioutil.ReadAll(request.Body); err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err := t.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := ioutil.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+	if err = t.recorder.recordTrip(&bridge.HttpRequest{
+		Method: request.Method,
+		URL:    request.URL.String(),
+		Header: request.Header,
+		Body:   asPayload(requestBody),
+	}, &bridge.HttpResponse{
+		Code:   response.StatusCode,
+		Header: response.Header,
+		Body:   asPayload(responseBody),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record trip: %v %v, %v", request.Method, request.URL, err)
+	}
+	return response, nil
+}
+
+type replayRoundTripper struct {
+	recorder *Recorder
+}
+
+func (t *replayRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.recorder.replayTrip(request)
+}
+
+func asPayload(content []byte) string {
+	if IsASCIIText(string(content)) {
+		return string(content)
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("base64:")
+	encoder := base64.NewEncoder(base64.StdEncoding, buf)
+	_, _ = encoder.Write(content)
+	_ = encoder.Close()
+	return buf.String()
+}
+
+func writeJSON(filename string, source interface{}) error {
+	buf, err := json.MarshalIndent(source, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf, 0644)
+}
+
+//NewRecorder creates a new recorder for supplied mode and base directory.
+func NewRecorder(mode, baseDirectory string) (*Recorder, error) {
+	if mode != RecorderModeRecord && mode != RecorderModeReplay {
+		return nil, fmt.Errorf("unsupported recorder mode: %v", mode)
+	}
+	if mode == RecorderModeReplay && !toolbox.FileExists(baseDirectory) {
+		return nil, fmt.Errorf("replay directory does not exist: %v", baseDirectory)
+	}
+	return &Recorder{
+		Mode:          mode,
+		BaseDirectory: baseDirectory,
+		mutex:         &sync.Mutex{},
+	}, nil
+}
@@ -0,0 +1,225 @@
+package endly_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/endly"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	err := StartRestTestServer(8117)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var recordDirectory = path.Join(os.TempDir(), "endly_recorder_test")
+	_ = os.RemoveAll(recordDirectory)
+	defer os.RemoveAll(recordDirectory)
+
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.RestServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var request = &endly.RestSendRequest{
+		URL:     "http://127.0.0.1:8117/reverse",
+		Method:  "POST",
+		Request: "{\"text\":\"abc\"}",
+	}
+
+	for _, mode := range []string{endly.RecorderModeRecord, endly.RecorderModeReplay} {
+		recorder, err := endly.NewRecorder(mode, recordDirectory)
+		if !assert.Nil(t, err, mode) {
+			return
+		}
+		context := manager.NewContext(toolbox.NewContext())
+		context.SetRecorder(recorder)
+		serviceResponse := service.Run(context, request)
+		context.Close()
+		if !assert.Equal(t, "", serviceResponse.Error, mode) {
+			return
+		}
+		response, ok := serviceResponse.Response.(*endly.RestSendResponse)
+		if assert.True(t, ok, mode) {
+			responseMap := toolbox.AsMap(response.Response)
+			assert.EqualValues(t, "cba", responseMap["text"], mode)
+		}
+	}
+	assert.True(t, toolbox.FileExists(path.Join(recordDirectory, "http", "bridge.HttpRequest-0.json")))
+}
+
+func TestNewRecorder(t *testing.T) {
+	_, err := endly.NewRecorder("abc", os.TempDir())
+	assert.NotNil(t, err)
+	_, err = endly.NewRecorder(endly.RecorderModeReplay, path.Join(os.TempDir(), "endly_recorder_missing"))
+	assert.NotNil(t, err)
+}
+
+func TestRecorder_HTTPRunner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		_, _ = writer.Write([]byte("echo:" + string(body)))
+	}))
+	var recordDirectory = path.Join(os.TempDir(), "endly_recorder_http_test")
+	_ = os.RemoveAll(recordDirectory)
+	defer os.RemoveAll(recordDirectory)
+
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.HTTPRunnerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var request = &endly.SendHTTPRequest{
+		Requests: []*endly.HTTPRequest{
+			{
+				Method: "POST",
+				URL:    server.URL + "/echo",
+				Body:   "abc",
+			},
+		},
+	}
+	for _, mode := range []string{endly.RecorderModeRecord, endly.RecorderModeReplay} {
+		if mode == endly.RecorderModeReplay {
+			//replay does not reach the server
+			server.Close()
+		}
+		recorder, err := endly.NewRecorder(mode, recordDirectory)
+		if !assert.Nil(t, err, mode) {
+			return
+		}
+		context := manager.NewContext(toolbox.NewContext())
+		context.SetRecorder(recorder)
+		serviceResponse := service.Run(context, request)
+		context.Close()
+		if !assert.Equal(t, "", serviceResponse.Error, mode) {
+			return
+		}
+		response, ok := serviceResponse.Response.(*endly.SendHTTPResponse)
+		if assert.True(t, ok, mode) && assert.Equal(t, 1, len(response.Responses), mode) {
+			assert.EqualValues(t, 200, response.Responses[0].Code, mode)
+			assert.EqualValues(t, "echo:abc", response.Responses[0].Body, mode)
+		}
+	}
+}
+
+func TestRecorder_RecordTripError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	//recorder base directory is a file, so trips can not be recorded
+	var recordFile = path.Join(os.TempDir(), "endly_recorder_file")
+	if !assert.Nil(t, ioutil.WriteFile(recordFile, []byte{}, 0644)) {
+		return
+	}
+	defer os.Remove(recordFile)
+	recorder, err := endly.NewRecorder(endly.RecorderModeRecord, recordFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	response, err := recorder.Transport(nil).RoundTrip(request)
+	assert.Nil(t, response)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "failed to record trip")
+	}
+}
+
+func TestRecorder_Transfer(t *testing.T) {
+	var baseDirectory = path.Join(os.TempDir(), "endly_recorder_transfer_test")
+	_ = os.RemoveAll(baseDirectory)
+	defer os.RemoveAll(baseDirectory)
+	var recordDirectory = path.Join(baseDirectory, "record")
+	var sourceFile = path.Join(baseDirectory, "source", "app.properties")
+	if !assert.Nil(t, os.MkdirAll(path.Dir(sourceFile), 0744)) {
+		return
+	}
+	if !assert.Nil(t, ioutil.WriteFile(sourceFile, []byte("port=$port"), 0644)) {
+		return
+	}
+	var targetFile = path.Join(baseDirectory, "target", "app.properties")
+
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var request = &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source: url.NewResource(toolbox.FileSchema + sourceFile),
+				Target: url.NewResource(toolbox.FileSchema + targetFile),
+				Expand: true,
+			},
+		},
+	}
+	for _, mode := range []string{endly.RecorderModeRecord, endly.RecorderModeReplay} {
+		if mode == endly.RecorderModeReplay {
+			//replay uses recorded source and memory target
+			assert.Nil(t, os.Remove(sourceFile))
+			assert.Nil(t, os.Remove(targetFile))
+		}
+		recorder, err := endly.NewRecorder(mode, recordDirectory)
+		if !assert.Nil(t, err, mode) {
+			return
+		}
+		context := manager.NewContext(toolbox.NewContext())
+		context.SetRecorder(recorder)
+		context.State().Put("port", "8080")
+		serviceResponse := service.Run(context, request)
+		context.Close()
+		if !assert.Equal(t, "", serviceResponse.Error, mode) {
+			return
+		}
+	}
+	assert.False(t, toolbox.FileExists(targetFile))
+	memStorage := storage.NewMemoryService()
+	object, err := memStorage.StorageObject(toolbox.FileSchema + targetFile)
+	if !assert.Nil(t, err) {
+		return
+	}
+	reader, err := memStorage.Download(object)
+	if assert.Nil(t, err) {
+		content, _ := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		assert.EqualValues(t, "port=8080", string(content))
+	}
+}
+
+//TestRecorder_Exec replays exec commands recorded under <base directory>/exec/<host_port>, capturing them requires a live ssh session
+func TestRecorder_Exec(t *testing.T) {
+	recorder, err := endly.NewRecorder(endly.RecorderModeReplay, "test/recorder")
+	if !assert.Nil(t, err) {
+		return
+	}
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	context.SetRecorder(recorder)
+	service, err := manager.Service(endly.ExecServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.CommandRequest{
+		Target:   url.NewResource("scp://127.0.0.1:22/"),
+		Commands: []string{"cat /etc/hostname"},
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	response, ok := serviceResponse.Response.(*endly.CommandResponse)
+	if assert.True(t, ok) {
+		assert.True(t, strings.Contains(response.Stdout(), "endly-test"), response.Stdout())
+	}
+}
@@ -307,6 +307,16 @@ func (r *CliRunner) reportEventType(serviceResponse interface{}, event *Event, f
 			r.printShortMessage(messageTypeGeneric, fmt.Sprintf("%v %v", actual.BuildSpec.Name, actual.Target.URL), messageTypeGeneric, "build")
 		}
 
+	case *BuildDiagnosticEventType:
+		r.reportBuildDiagnostics(actual)
+
+	case *ProcessRestartEventType:
+		if actual.Error != "" {
+			r.printShortMessage(messageTypeError, fmt.Sprintf("%v (pid: %v), %v", actual.Command, actual.Pid, actual.Error), messageTypeError, "restart")
+		} else {
+			r.printShortMessage(messageTypeGeneric, fmt.Sprintf("%v (pid: %v -> %v), restarts: %v", actual.Command, actual.Pid, actual.NewPid, actual.Restarts), messageTypeGeneric, "restart")
+		}
+
 	case *CopyEventType:
 		if filter.Transfer {
 			r.printShortMessage(messageTypeGeneric, fmt.Sprintf("expand: %v", actual.Expand), messageTypeGeneric, "copy")
@@ -435,6 +445,20 @@ func (r *CliRunner) reportTagSummary() {
 	}
 }
 
+func (r *CliRunner) reportBuildDiagnostics(event *BuildDiagnosticEventType) {
+	for _, diagnostic := range event.Diagnostics {
+		var location = diagnostic.File
+		if diagnostic.Line > 0 {
+			location = fmt.Sprintf("%v:%v", diagnostic.File, diagnostic.Line)
+		}
+		var message = strings.TrimSpace(fmt.Sprintf("%v %v", location, diagnostic.Message))
+		if diagnostic.Test != "" {
+			message = strings.TrimSpace(fmt.Sprintf("%v: %v", diagnostic.Test, message))
+		}
+		r.printShortMessage(messageTypeError, message, messageTypeError, event.Name)
+	}
+}
+
 func (r *CliRunner) reportLookupErrors(response *SeleniumRunResponse) {
 	if len(response.LookupErrors) > 0 {
 		for _, errMessage := range response.LookupErrors {
@@ -160,6 +160,26 @@ func (s *buildService) build(context *Context, request *BuildRequest) (*BuildRes
 		return nil, err
 	}
 	state.Put("buildSpec", buildState)
+
+	var store *url.Resource
+	var artifactName, artifactLocation string
+	if request.Artifacts != nil {
+		if store, err = context.ExpandResource(request.Artifacts.Store); err != nil {
+			return nil, err
+		}
+		artifactName, artifactLocation = s.artifactLocation(context, request, target, store)
+	}
+	if artifactLocation != "" {
+		result.ArtifactURL = artifactLocation
+		restored, err := s.restoreArtifacts(context, store, artifactLocation, target, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore artifacts from %v, %v", artifactLocation, err)
+		}
+		if restored {
+			publishBuildArtifacts(context, artifactName, artifactLocation)
+			return result, nil
+		}
+	}
 	err = s.setSdkIfNeeded(context, request)
 	if err != nil {
 		return nil, err
@@ -177,7 +197,7 @@ func (s *buildService) build(context *Context, request *BuildRequest) (*BuildRes
 	}
 	commandInfo, err := context.Execute(target, goal.Command)
 	if err != nil {
-		return nil, err
+		return s.reportDiagnostics(context, buildSpec, target, result, err)
 	}
 	result.CommandInfo = commandInfo
 
@@ -188,14 +208,57 @@ func (s *buildService) build(context *Context, request *BuildRequest) (*BuildRes
 		}
 	}
 
+	var output = commandInfo.Stdout()
 	if goal.VerificationCommand != nil {
-		_, err = context.Execute(target, goal.VerificationCommand)
+		verificationInfo, err := context.Execute(target, goal.VerificationCommand)
 		if err != nil {
+			return s.reportDiagnostics(context, buildSpec, target, result, fmt.Errorf("%v\n%v", err, output))
+		}
+		output += "\n" + verificationInfo.Stdout()
+	}
+	if goal.Output != nil {
+		if err = goal.Output.Parse(output, result); err != nil {
 			return nil, err
 		}
 	}
+	if artifactLocation != "" {
+		if err = s.collectArtifacts(context, goal, store, artifactLocation, target, result); err != nil {
+			return nil, fmt.Errorf("failed to collect artifacts into %v, %v", artifactLocation, err)
+		}
+		publishBuildArtifacts(context, artifactName, artifactLocation)
+	}
 	return result, nil
 }
+
+//reportDiagnostics parses failed build output with the build spec diagnostic parser, diagnostics are set on the response and reported as event
+func (s *buildService) reportDiagnostics(context *Context, spec *BuildSpec, target *url.Resource, response *BuildResponse, buildError error) (*BuildResponse, error) {
+	parser, has := BuildDiagnosticParsers[spec.Name]
+	if !has {
+		return nil, buildError
+	}
+	response.Diagnostics = parser(buildError.Error())
+	if len(response.Diagnostics) == 0 {
+		return nil, buildError
+	}
+	var event = &BuildDiagnosticEventType{
+		Name:        spec.Name,
+		TargetURL:   target.URL,
+		Diagnostics: response.Diagnostics,
+	}
+	AddEvent(context, event, Pairs("value", event), Info)
+	return response, buildError
+}
+
+//publishBuildArtifacts publishes artifact store location under buildArtifacts state key
+func publishBuildArtifacts(context *Context, name, location string) {
+	var state = context.State()
+	var artifacts = state.GetMap(BuildArtifactsStateKey)
+	if artifacts == nil {
+		artifacts = data.NewMap()
+		state.Put(BuildArtifactsStateKey, artifacts)
+	}
+	artifacts.Put(name, location)
+}
 func newBuildState(buildSepc *BuildSpec, target *url.Resource, request *BuildRequest, context *Context) (data.Map, error) {
 	target, err := context.ExpandResource(request.Target)
 	if err != nil {
@@ -0,0 +1,225 @@
+package endly
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+//BuildArtifactsStateKey represents a state key holding artifact store locations keyed by artifact name, i.e. deployment transfer source can use ${buildArtifacts.myapp}/myapp.jar
+const BuildArtifactsStateKey = "buildArtifacts"
+
+//BuildArtifacts represents build artifacts store settings, artifacts matching build goal globs are stored under <store>/<name>/<revision>-<build hash>/
+type BuildArtifacts struct {
+	Store    *url.Resource //artifact store, a local directory or any storage URL
+	Name     string        //artifact name, build spec name by default
+	Revision string        //source revision, build target checkout revision by default, artifacts are not cached without revision
+}
+
+//Validate checks if artifacts settings are valid
+func (a *BuildArtifacts) Validate() error {
+	if a.Store == nil || a.Store.URL == "" {
+		return fmt.Errorf("artifacts store was empty")
+	}
+	return nil
+}
+
+//buildSpecHash returns a short hash of the expanded build specification and build target URL
+func buildSpecHash(context *Context, spec *BuildSpec, target *url.Resource) string {
+	var fragments = []string{spec.Name, spec.Version, spec.Goal, spec.BuildGoal, spec.Args, spec.Sdk, spec.SdkVersion}
+	for i, fragment := range fragments {
+		fragments[i] = context.Expand(fragment)
+	}
+	fragments = append(fragments, target.URL)
+	var hash = md5.Sum([]byte(strings.Join(fragments, "|")))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+//targetRevision returns revision of the build target checkout, target type defaults to git
+func (s *buildService) targetRevision(context *Context, target *url.Resource) string {
+	service, err := context.Service(VersionControlServiceID)
+	if err != nil {
+		return ""
+	}
+	var vcTarget = url.NewResource(target.URL, target.Credential)
+	vcTarget.Type = target.Type
+	if vcTarget.Type == "" {
+		vcTarget.Type = "git"
+	}
+	response := service.Run(context, &VcStatusRequest{Target: vcTarget})
+	if info, ok := response.Response.(*VcInfo); ok && response.Error == "" {
+		return info.Revision
+	}
+	return ""
+}
+
+//artifactLocation returns artifact store location for the build, or empty string if revision is unknown
+func (s *buildService) artifactLocation(context *Context, request *BuildRequest, target, store *url.Resource) (string, string) {
+	var artifacts = request.Artifacts
+	var name = context.Expand(artifacts.Name)
+	if name == "" {
+		name = request.BuildSpec.Name
+	}
+	var revision = context.Expand(artifacts.Revision)
+	if revision == "" {
+		revision = s.targetRevision(context, target)
+	}
+	if revision == "" {
+		return name, ""
+	}
+	return name, toolbox.URLPathJoin(store.URL, path.Join(name, revision+"-"+buildSpecHash(context, request.BuildSpec, target)))
+}
+
+//buildArtifactsMarker represents a file written into the store location once all artifacts were collected, location without the marker is not restored
+const buildArtifactsMarker = ".collected"
+
+//listArtifactFiles returns files matching artifact glob patterns keyed by relative path, only directories on the pattern path are listed, so *.tgz does not scan node_modules
+func listArtifactFiles(service storage.Service, baseURL string, patterns []string) (map[string]storage.Object, error) {
+	var result = make(map[string]storage.Object)
+	if exists, err := service.Exists(baseURL); err != nil || !exists {
+		return result, err
+	}
+	for _, pattern := range patterns {
+		if err := collectArtifactFiles(service, baseURL, "", strings.Split(strings.TrimPrefix(pattern, "/"), "/"), result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//collectArtifactFiles matches the first pattern segment against URL entries, matched folders are listed with remaining segments, pattern ending with / collects the whole directory
+func collectArtifactFiles(service storage.Service, URL, relativePath string, segments []string, result map[string]storage.Object) error {
+	objects, err := service.List(URL)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if strings.TrimRight(object.URL(), "/") == strings.TrimRight(URL, "/") {
+			continue
+		}
+		_, name := toolbox.URLSplit(object.URL())
+		if matched, _ := path.Match(segments[0], name); !matched {
+			continue
+		}
+		var objectPath = path.Join(relativePath, name)
+		switch {
+		case len(segments) == 1:
+			if !object.IsFolder() {
+				result[objectPath] = object
+			}
+		case !object.IsFolder():
+		case len(segments) == 2 && segments[1] == "":
+			err = collectStorageFiles(service, object.URL(), objectPath, result)
+		default:
+			err = collectArtifactFiles(service, object.URL(), objectPath, segments[1:], result)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//copyArtifacts copies files keyed by relative path into target, it returns sorted copied relative paths
+func copyArtifacts(sourceService storage.Service, files map[string]storage.Object, targetService storage.Service, targetURL string) ([]string, error) {
+	var result = toolbox.MapKeysToStringSlice(files)
+	sort.Strings(result)
+	for _, relativePath := range result {
+		if err := storage.Copy(sourceService, files[relativePath].URL(), targetService, toolbox.URLPathJoin(targetURL, relativePath), nil, nil); err != nil {
+			return nil, fmt.Errorf("failed to copy artifact %v, %v", relativePath, err)
+		}
+	}
+	return result, nil
+}
+
+//restoreArtifacts restores cached artifacts into the target, it returns false if there are no completely collected artifacts
+func (s *buildService) restoreArtifacts(context *Context, store *url.Resource, location string, target *url.Resource, response *BuildResponse) (bool, error) {
+	storeService, err := getStorageService(context, store)
+	if err != nil {
+		return false, err
+	}
+	defer storeService.Close()
+	if exists, _ := storeService.Exists(toolbox.URLPathJoin(location, buildArtifactsMarker)); !exists {
+		return false, nil
+	}
+	files, err := listStorageFiles(storeService, location)
+	if err != nil {
+		return false, err
+	}
+	delete(files, buildArtifactsMarker)
+	targetService, err := getStorageService(context, target)
+	if err != nil {
+		return false, err
+	}
+	defer targetService.Close()
+	restored, err := copyArtifacts(storeService, files, targetService, target.URL)
+	if err != nil || len(restored) == 0 {
+		return false, err
+	}
+	for _, relativePath := range restored {
+		response.addArtifact(path.Join(target.ParsedURL.Path, relativePath))
+	}
+	response.Restored = true
+	return true, nil
+}
+
+//collectArtifacts stores target files matching goal artifact globs in the store location, previous partial collection is removed first and
+//the marker is written last, so interrupted collection is never restored
+func (s *buildService) collectArtifacts(context *Context, goal *BuildGoal, store *url.Resource, location string, target *url.Resource, response *BuildResponse) error {
+	if len(goal.Artifacts) == 0 {
+		return nil
+	}
+	var patterns = make([]string, len(goal.Artifacts))
+	for i, pattern := range goal.Artifacts {
+		patterns[i] = context.Expand(pattern)
+	}
+	targetService, err := getStorageService(context, target)
+	if err != nil {
+		return err
+	}
+	defer targetService.Close()
+	storeService, err := getStorageService(context, store)
+	if err != nil {
+		return err
+	}
+	defer storeService.Close()
+	if exists, _ := storeService.Exists(location); exists {
+		object, err := storeService.StorageObject(location)
+		if err == nil {
+			err = storeService.Delete(object)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove partial artifacts, %v", err)
+		}
+	}
+	files, err := listArtifactFiles(targetService, target.URL, patterns)
+	if err != nil {
+		return err
+	}
+	collected, err := copyArtifacts(targetService, files, storeService, location)
+	if err != nil || len(collected) == 0 {
+		return err
+	}
+	if err = storeService.Upload(toolbox.URLPathJoin(location, buildArtifactsMarker), strings.NewReader(strings.Join(collected, "\n"))); err != nil {
+		return err
+	}
+	for _, relativePath := range collected {
+		response.addArtifact(path.Join(target.ParsedURL.Path, relativePath))
+	}
+	return nil
+}
+
+func (r *BuildResponse) addArtifact(artifact string) {
+	for _, candidate := range r.Artifacts {
+		if candidate == artifact {
+			return
+		}
+	}
+	r.Artifacts = append(r.Artifacts, artifact)
+}
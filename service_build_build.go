@@ -20,13 +20,19 @@ type BuildSpec struct {
 //BuildRequest represents a build request.
 type BuildRequest struct {
 	MetaURL   string
-	BuildSpec *BuildSpec    //build specification
-	Target    *url.Resource //path to application to be build, Note that command may use $build.target variable. that expands to Target URL path
+	BuildSpec *BuildSpec      //build specification
+	Target    *url.Resource   //path to application to be build, Note that command may use $build.target variable. that expands to Target URL path
+	Artifacts *BuildArtifacts //optional artifact store, artifacts cached for the same revision and build spec are restored instead of building
 }
 
 //BuildResponse represents a build response.
 type BuildResponse struct {
 	CommandInfo *CommandResponse
+	Tests       *BuildTests        //test counts, nil if build goal has no test output rules
+	Artifacts   []string           //artifact paths matched by build goal output rules or collected by build goal artifact globs
+	ArtifactURL string             //artifact store location
+	Restored    bool               //true if artifacts were restored from the store instead of building
+	Diagnostics []*BuildDiagnostic //compiler errors and failed tests parsed from failed build output
 }
 
 //Validate validates if request is valid
@@ -40,5 +46,8 @@ func (r *BuildRequest) Validate() error {
 	if r.BuildSpec.Goal == "" {
 		return fmt.Errorf("BuildSpec.Goal was empty for %v", r.BuildSpec.Name)
 	}
+	if r.Artifacts != nil {
+		return r.Artifacts.Validate()
+	}
 	return nil
 }
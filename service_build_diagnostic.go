@@ -0,0 +1,143 @@
+package endly
+
+import (
+	"github.com/lunixbochs/vtclean"
+	"github.com/viant/toolbox"
+	"regexp"
+	"strings"
+)
+
+//BuildDiagnostic represents a compiler error or failed test parsed from build output
+type BuildDiagnostic struct {
+	File    string
+	Line    int
+	Message string
+	Test    string //failed test name, empty for compiler errors
+}
+
+//BuildDiagnosticEventType represents an event reporting diagnostics of a failed build
+type BuildDiagnosticEventType struct {
+	Name        string
+	TargetURL   string
+	Diagnostics []*BuildDiagnostic
+}
+
+//BuildDiagnosticParsers represents build output diagnostic parsers keyed by BuildSpec.Name
+var BuildDiagnosticParsers = map[string]func(output string) []*BuildDiagnostic{
+	"go":    parseGoDiagnostics,
+	"maven": parseMavenDiagnostics,
+	"javac": parseJavacDiagnostics,
+}
+
+var goCompilerErrorExpr = regexp.MustCompile(`^(\S+\.go):(\d+)(?::\d+)?:\s+(.+)$`)
+var goTestFailureExpr = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+var goTestLocationExpr = regexp.MustCompile(`^\s+(\S+\.go):(\d+):\s*(.*)$`)
+var javacErrorExpr = regexp.MustCompile(`^(?:\[ERROR\]\s+)?(\S+\.java):\[?(\d+)(?:,\d+\])?:?\s*(?:error:\s*)?(.+)$`)
+var surefireFailureExpr = regexp.MustCompile(`^(?:\[ERROR\]\s+)?(\S+?)(?:\((\S+)\))?\s+Time elapsed:.*<<< (?:FAILURE|ERROR)!`)
+var javaStackFrameExpr = regexp.MustCompile(`^\s*at \S+\((\w+\.java):(\d+)\)`)
+
+func buildOutputLines(output string) []string {
+	var lines = strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(vtclean.Clean(line, false), "\r")
+	}
+	return lines
+}
+
+//parseGoDiagnostics parses go build compiler errors and go test failures
+func parseGoDiagnostics(output string) []*BuildDiagnostic {
+	var result = make([]*BuildDiagnostic, 0)
+	var failedTest *BuildDiagnostic
+	for _, line := range buildOutputLines(output) {
+		if matched := goTestFailureExpr.FindStringSubmatch(line); len(matched) > 0 {
+			failedTest = &BuildDiagnostic{Test: matched[1]}
+			result = append(result, failedTest)
+			continue
+		}
+		if failedTest != nil {
+			if matched := goTestLocationExpr.FindStringSubmatch(line); len(matched) > 0 {
+				if failedTest.File != "" {
+					failedTest = &BuildDiagnostic{Test: failedTest.Test}
+					result = append(result, failedTest)
+				}
+				failedTest.File = matched[1]
+				failedTest.Line = toolbox.AsInt(matched[2])
+				failedTest.Message = strings.TrimSpace(matched[3])
+				continue
+			}
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				if failedTest.File != "" && failedTest.Message == "" {
+					failedTest.Message = strings.TrimSpace(line)
+				}
+				continue
+			}
+			failedTest = nil
+		}
+		if matched := goCompilerErrorExpr.FindStringSubmatch(line); len(matched) > 0 {
+			result = append(result, &BuildDiagnostic{
+				File:    matched[1],
+				Line:    toolbox.AsInt(matched[2]),
+				Message: matched[3],
+			})
+		}
+	}
+	return result
+}
+
+//parseJavacDiagnostics parses javac compiler errors, including the ones reported by maven compiler plugin
+func parseJavacDiagnostics(output string) []*BuildDiagnostic {
+	var result = make([]*BuildDiagnostic, 0)
+	for _, line := range buildOutputLines(output) {
+		if matched := javacErrorExpr.FindStringSubmatch(line); len(matched) > 0 {
+			result = append(result, &BuildDiagnostic{
+				File:    matched[1],
+				Line:    toolbox.AsInt(matched[2]),
+				Message: strings.TrimSpace(matched[3]),
+			})
+		}
+	}
+	return result
+}
+
+//parseSurefireDiagnostics parses maven surefire test failures, location is taken from the first stack frame of the test class
+func parseSurefireDiagnostics(output string) []*BuildDiagnostic {
+	var result = make([]*BuildDiagnostic, 0)
+	var failedTest *BuildDiagnostic
+	var testClass string
+	for _, line := range buildOutputLines(output) {
+		if matched := surefireFailureExpr.FindStringSubmatch(line); len(matched) > 0 {
+			failedTest = &BuildDiagnostic{Test: matched[1]}
+			testClass = matched[1]
+			if matched[2] != "" {
+				failedTest.Test = matched[2] + "." + matched[1]
+				testClass = matched[2]
+			} else if index := strings.LastIndex(testClass, "."); index != -1 {
+				testClass = string(testClass[:index])
+			}
+			if index := strings.LastIndex(testClass, "."); index != -1 {
+				testClass = string(testClass[index+1:])
+			}
+			result = append(result, failedTest)
+			continue
+		}
+		if failedTest == nil {
+			continue
+		}
+		if matched := javaStackFrameExpr.FindStringSubmatch(line); len(matched) > 0 {
+			if failedTest.File == "" && strings.TrimSuffix(matched[1], ".java") == testClass {
+				failedTest.File = matched[1]
+				failedTest.Line = toolbox.AsInt(matched[2])
+			}
+			continue
+		}
+		if failedTest.Message == "" && strings.TrimSpace(line) != "" {
+			failedTest.Message = strings.TrimSpace(line)
+		}
+	}
+	return result
+}
+
+//parseMavenDiagnostics parses javac compiler errors and surefire test failures
+func parseMavenDiagnostics(output string) []*BuildDiagnostic {
+	return append(parseJavacDiagnostics(output), parseSurefireDiagnostics(output)...)
+}
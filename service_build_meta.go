@@ -1,6 +1,12 @@
 package endly
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/lunixbochs/vtclean"
+	"github.com/viant/toolbox"
+	"regexp"
+	"strings"
+)
 
 //BuildGoal builds goal represents a build goal
 type BuildGoal struct {
@@ -9,6 +15,25 @@ type BuildGoal struct {
 	Command             *ExtractableCommand
 	PostTransfers       *TransferCopyRequest
 	VerificationCommand *ExtractableCommand
+	Output              *BuildOutput //optional test counts and artifacts extraction rules
+	Artifacts           []string     //artifact glob patterns relative to build path collected into artifact store, i.e. target/*.jar, each path segment is matched separately, pattern ending with / collects the whole directory
+}
+
+//BuildOutput represents regular expressions extracting test counts and artifact paths from build and verification output lines,
+// numeric groups of all matching lines are summed up, expression without a group counts matching lines
+type BuildOutput struct {
+	TestsRun     string //expression matching number of executed tests
+	TestsFailed  string //expression matching number of failed tests
+	TestsSkipped string //expression matching number of skipped tests
+	Artifact     string //expression with a group matching artifact path
+}
+
+//BuildTests represents build test counts
+type BuildTests struct {
+	Run     int
+	Passed  int
+	Failed  int
+	Skipped int
 }
 
 //BuildMeta build meta provides instruction how to build an app
@@ -30,3 +55,76 @@ func (m *BuildMeta) Validate() error {
 	}
 	return nil
 }
+
+func sumBuildOutputMatches(expression string, lines []string) (int, error) {
+	if expression == "" {
+		return 0, nil
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression: %v, %v", expression, err)
+	}
+	var result = 0
+	for _, line := range lines {
+		matched := compiled.FindStringSubmatch(line)
+		if len(matched) == 0 {
+			continue
+		}
+		if len(matched) == 1 {
+			result++
+			continue
+		}
+		for _, group := range matched[1:] {
+			if group != "" {
+				result += toolbox.AsInt(group)
+			}
+		}
+	}
+	return result, nil
+}
+
+//Parse extracts test counts and artifact paths from supplied output into the response
+func (o *BuildOutput) Parse(output string, response *BuildResponse) error {
+	var lines = strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(vtclean.Clean(line, false), "\r")
+	}
+	if o.TestsRun != "" || o.TestsFailed != "" || o.TestsSkipped != "" {
+		var tests = &BuildTests{}
+		var err error
+		if tests.Run, err = sumBuildOutputMatches(o.TestsRun, lines); err != nil {
+			return err
+		}
+		if tests.Failed, err = sumBuildOutputMatches(o.TestsFailed, lines); err != nil {
+			return err
+		}
+		if tests.Skipped, err = sumBuildOutputMatches(o.TestsSkipped, lines); err != nil {
+			return err
+		}
+		if tests.Passed = tests.Run - tests.Failed - tests.Skipped; tests.Passed < 0 {
+			tests.Passed = 0
+		}
+		response.Tests = tests
+	}
+	if o.Artifact == "" {
+		return nil
+	}
+	compiled, err := regexp.Compile(o.Artifact)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %v, %v", o.Artifact, err)
+	}
+	var indexed = make(map[string]bool)
+	for _, artifact := range response.Artifacts {
+		indexed[artifact] = true
+	}
+	for _, line := range lines {
+		matched := compiled.FindStringSubmatch(line)
+		if len(matched) < 2 || indexed[strings.TrimSpace(matched[1])] {
+			continue
+		}
+		var artifact = strings.TrimSpace(matched[1])
+		indexed[artifact] = true
+		response.Artifacts = append(response.Artifacts, artifact)
+	}
+	return nil
+}
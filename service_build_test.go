@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
 	"strings"
@@ -97,3 +98,337 @@ func TestBuildService_Build(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildService_LoadMeta(t *testing.T) {
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.BuildServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, name := range []string{"go", "maven", "npm", "yarn", "gradle", "make", "docker"} {
+		serviceResponse := service.Run(context, &endly.BuildLoadMetaRequest{
+			Source: url.NewResource(fmt.Sprintf("meta/build/%v.json", name)),
+		})
+		if !assert.Equal(t, "", serviceResponse.Error, name) {
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.BuildLoadMetaResponse)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, name, response.Meta.Name)
+			assert.Nil(t, response.Meta.Validate(), name)
+		}
+	}
+}
+
+func TestBuildService_Metas(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/tmp/app", credentialFile)
+	var manager = endly.NewManager()
+	var useCases = []struct {
+		Name       string
+		BuildGoal  string
+		Args       string
+		Dependency string
+		Passed     string
+		Failed     string
+	}{
+		{
+			Name:       "npm",
+			BuildGoal:  "build",
+			Args:       "--silent",
+			Dependency: "node",
+			Passed:     "built dist/js/app.js",
+			Failed:     "npm ERR! missing: express",
+		},
+		{
+			Name:       "yarn",
+			BuildGoal:  "build",
+			Args:       "--silent",
+			Dependency: "node",
+			Passed:     "built dist/js/app.js",
+			Failed:     "not installed",
+		},
+		{
+			Name:       "gradle",
+			BuildGoal:  "build",
+			Args:       "--console=plain",
+			Dependency: "gradle",
+			Passed:     "BUILD SUCCESSFUL",
+			Failed:     "No such file or directory",
+		},
+		{
+			Name:      "make",
+			BuildGoal: "all",
+			Args:      "CC=gcc",
+			Passed:    "make exit code: 0",
+			Failed:    "make exit code: 2",
+		},
+		{
+			Name:      "docker",
+			BuildGoal: "build",
+			Args:      "-t app:1.0",
+			Passed:    "Successfully tagged app:1.0",
+			Failed:    "returned a non-zero code",
+		},
+	}
+	for _, useCase := range useCases {
+		execService, err := GetReplayService(fmt.Sprintf("test/build/meta/%v/linux", useCase.Name))
+		if !assert.Nil(t, err, useCase.Name) {
+			continue
+		}
+		context, err := OpenTestContext(manager, target, execService)
+		if !assert.Nil(t, err, useCase.Name) {
+			continue
+		}
+		if useCase.Dependency != "" {
+			//dependency deployed in the session is not deployed again
+			session, err := context.TerminalSession(target)
+			if !assert.Nil(t, err, useCase.Name) {
+				context.Close()
+				continue
+			}
+			session.Deployed[useCase.Dependency] = ""
+		}
+		service, err := context.Service(endly.BuildServiceID)
+		if !assert.Nil(t, err, useCase.Name) {
+			context.Close()
+			continue
+		}
+		var request = &endly.BuildRequest{
+			Target: target,
+			BuildSpec: &endly.BuildSpec{
+				Name:      useCase.Name,
+				Goal:      "build",
+				BuildGoal: useCase.BuildGoal,
+				Args:      useCase.Args,
+			},
+		}
+		//first build passes, the second one fails with the same commands
+		serviceResponse := service.Run(context, request)
+		if assert.Equal(t, "", serviceResponse.Error, useCase.Name) {
+			response, ok := serviceResponse.Response.(*endly.BuildResponse)
+			if assert.True(t, ok, useCase.Name) {
+				var stdout = response.CommandInfo.Stdout()
+				assert.True(t, strings.Contains(stdout, useCase.Passed), useCase.Name+": "+stdout)
+			}
+		}
+		serviceResponse = service.Run(context, request)
+		assert.True(t, strings.Contains(serviceResponse.Error, useCase.Failed), useCase.Name+": "+serviceResponse.Error)
+		context.Close()
+	}
+}
+
+func TestBuildOutput_Parse(t *testing.T) {
+	var useCases = []struct {
+		Description string
+		Output      *endly.BuildOutput
+		Stdout      string
+		Tests       *endly.BuildTests
+		Artifacts   []string
+	}{
+		{
+			Description: "maven",
+			Output: &endly.BuildOutput{
+				TestsRun:     `^(?:\[\w+\] )?Tests run: (\d+), Failures: \d+, Errors: \d+, Skipped: \d+\s*$`,
+				TestsFailed:  `^(?:\[\w+\] )?Tests run: \d+, Failures: (\d+), Errors: (\d+), Skipped: \d+\s*$`,
+				TestsSkipped: `^(?:\[\w+\] )?Tests run: \d+, Failures: \d+, Errors: \d+, Skipped: (\d+)\s*$`,
+				Artifact:     `Building (?:jar|war|ear): (\S+)`,
+			},
+			Stdout: "[INFO] Tests run: 4, Failures: 1, Errors: 0, Skipped: 1, Time elapsed: 0.1 s - in com.viant.DummyTest\r\n" +
+				"[INFO] Tests run: 4, Failures: 1, Errors: 0, Skipped: 1\r\n" +
+				"[INFO] Building jar: /tmp/project1/target/project1-1.0.jar\r\n",
+			Tests:     &endly.BuildTests{Run: 4, Passed: 2, Failed: 1, Skipped: 1},
+			Artifacts: []string{"/tmp/project1/target/project1-1.0.jar"},
+		},
+		{
+			Description: "jest",
+			Output: &endly.BuildOutput{
+				TestsRun:     `^Tests:.*?(\d+) total|^\s+(\d+) (?:passing|failing|pending)\b`,
+				TestsFailed:  `^Tests:.*?(\d+) failed|^\s+(\d+) failing\b`,
+				TestsSkipped: `^Tests:.*?(\d+) skipped|^\s+(\d+) pending\b`,
+			},
+			Stdout: "Test Suites: 2 passed, 2 total\nTests:       1 failed, 2 skipped, 7 passed, 10 total\n",
+			Tests:  &endly.BuildTests{Run: 10, Passed: 7, Failed: 1, Skipped: 2},
+		},
+		{
+			Description: "go",
+			Output: &endly.BuildOutput{
+				TestsRun:     `^\s*--- (?:PASS|FAIL|SKIP):`,
+				TestsFailed:  `^\s*--- FAIL:`,
+				TestsSkipped: `^\s*--- SKIP:`,
+			},
+			Stdout: "=== RUN   TestA\n--- PASS: TestA (0.00s)\n=== RUN   TestB\n--- FAIL: TestB (0.00s)\nFAIL\n",
+			Tests:  &endly.BuildTests{Run: 2, Passed: 1, Failed: 1},
+		},
+	}
+	for _, useCase := range useCases {
+		var response = &endly.BuildResponse{}
+		err := useCase.Output.Parse(useCase.Stdout, response)
+		if !assert.Nil(t, err, useCase.Description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.Tests, response.Tests, useCase.Description)
+		assert.EqualValues(t, useCase.Artifacts, response.Artifacts, useCase.Description)
+	}
+}
+
+func TestBuildService_Artifacts(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/tmp/app", credentialFile)
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("scp://127.0.0.1:22/tmp/app/app-1.0.0.tgz", strings.NewReader("tgz"))
+	memStorage.Upload("scp://127.0.0.1:22/tmp/app/node_modules/dep/dep-2.0.0.tgz", strings.NewReader("dep"))
+	memStorage.Upload("scp://127.0.0.1:22/tmp/app/dist/js/app.js", strings.NewReader("js"))
+
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/build/artifacts/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.BuildServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.BuildLoadMetaRequest{
+		Source: url.NewResource("test/build/artifacts/meta.json"),
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	var build = func(revision string) *endly.BuildResponse {
+		serviceResponse := service.Run(context, &endly.BuildRequest{
+			Target:    target,
+			BuildSpec: &endly.BuildSpec{Name: "artifactApp", Goal: "build"},
+			Artifacts: &endly.BuildArtifacts{
+				Store:    url.NewResource("mem:///artifacts"),
+				Name:     "app",
+				Revision: revision,
+			},
+		})
+		if !assert.Equal(t, "", serviceResponse.Error, revision) {
+			return nil
+		}
+		response, ok := serviceResponse.Response.(*endly.BuildResponse)
+		assert.True(t, ok, revision)
+		return response
+	}
+
+	//first build collects top level tgz and dist directory, node_modules are not scanned
+	response := build("abc123")
+	if response == nil {
+		return
+	}
+	assert.False(t, response.Restored)
+	assert.True(t, strings.HasPrefix(response.ArtifactURL, "mem:///artifacts/app/abc123-"), response.ArtifactURL)
+	assert.Equal(t, []string{"/tmp/app/app-1.0.0.tgz", "/tmp/app/dist/js/app.js"}, response.Artifacts)
+	var location = response.ArtifactURL
+	for _, relativePath := range []string{"app-1.0.0.tgz", "dist/js/app.js", ".collected"} {
+		exists, _ := memStorage.Exists(toolbox.URLPathJoin(location, relativePath))
+		assert.True(t, exists, relativePath)
+	}
+	exists, _ := memStorage.Exists(toolbox.URLPathJoin(location, "node_modules/dep/dep-2.0.0.tgz"))
+	assert.False(t, exists)
+	assert.Equal(t, location, state.GetMap(endly.BuildArtifactsStateKey).GetString("app"))
+
+	//second build restores collected artifacts without running the build
+	if object, err := memStorage.StorageObject("scp://127.0.0.1:22/tmp/app/app-1.0.0.tgz"); assert.Nil(t, err) {
+		assert.Nil(t, memStorage.Delete(object))
+	}
+	response = build("abc123")
+	if response == nil {
+		return
+	}
+	assert.True(t, response.Restored)
+	assert.Equal(t, location, response.ArtifactURL)
+	assert.Equal(t, []string{"/tmp/app/app-1.0.0.tgz", "/tmp/app/dist/js/app.js"}, response.Artifacts)
+	exists, _ = memStorage.Exists("scp://127.0.0.1:22/tmp/app/app-1.0.0.tgz")
+	assert.True(t, exists)
+
+	//interrupted collection without the marker is not restored, the build runs and replaces partial artifacts
+	var partialLocation = strings.Replace(location, "abc123", "def456", 1)
+	memStorage.Upload(toolbox.URLPathJoin(partialLocation, "stale.tgz"), strings.NewReader("stale"))
+	response = build("def456")
+	if response == nil {
+		return
+	}
+	assert.False(t, response.Restored)
+	assert.Equal(t, partialLocation, response.ArtifactURL)
+	exists, _ = memStorage.Exists(toolbox.URLPathJoin(partialLocation, "stale.tgz"))
+	assert.False(t, exists)
+	exists, _ = memStorage.Exists(toolbox.URLPathJoin(partialLocation, ".collected"))
+	assert.True(t, exists)
+
+	//revision defaults to the build target checkout revision, build hash includes the target URL
+	response = build("")
+	if response == nil {
+		return
+	}
+	assert.False(t, response.Restored)
+	assert.Equal(t, strings.Replace(location, "abc123", "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c", 1), response.ArtifactURL)
+}
+
+func TestBuildDiagnosticParsers(t *testing.T) {
+	var useCases = []struct {
+		Name     string
+		Output   string
+		Expected []*endly.BuildDiagnostic
+	}{
+		{
+			Name:   "go",
+			Output: "# github.com/viant/app\r\n./main.go:12:5: undefined: foo\r\n",
+			Expected: []*endly.BuildDiagnostic{
+				{File: "./main.go", Line: 12, Message: "undefined: foo"},
+			},
+		},
+		{
+			Name:   "go",
+			Output: "=== RUN   TestApp\n--- FAIL: TestApp (0.00s)\n    app_test.go:23: expected 1 but had 2\nFAIL\nFAIL\tgithub.com/viant/app\t0.011s\n",
+			Expected: []*endly.BuildDiagnostic{
+				{File: "app_test.go", Line: 23, Message: "expected 1 but had 2", Test: "TestApp"},
+			},
+		},
+		{
+			Name:   "javac",
+			Output: "src/main/java/com/viant/Dummy.java:12: error: cannot find symbol\n",
+			Expected: []*endly.BuildDiagnostic{
+				{File: "src/main/java/com/viant/Dummy.java", Line: 12, Message: "cannot find symbol"},
+			},
+		},
+		{
+			Name: "maven",
+			Output: "[ERROR] /tmp/project1/src/main/java/com/viant/Dummy.java:[12,5] cannot find symbol\n" +
+				"Running com.viant.DummyTest\n" +
+				"Tests run: 2, Failures: 1, Errors: 0, Skipped: 0, Time elapsed: 0.02 sec <<< FAILURE! - in com.viant.DummyTest\n" +
+				"testSum(com.viant.DummyTest)  Time elapsed: 0.01 sec  <<< FAILURE!\n" +
+				"java.lang.AssertionError: expected:<3> but was:<2>\n" +
+				"\tat org.junit.Assert.fail(Assert.java:88)\n" +
+				"\tat com.viant.DummyTest.testSum(DummyTest.java:23)\n",
+			Expected: []*endly.BuildDiagnostic{
+				{File: "/tmp/project1/src/main/java/com/viant/Dummy.java", Line: 12, Message: "cannot find symbol"},
+				{File: "DummyTest.java", Line: 23, Message: "java.lang.AssertionError: expected:<3> but was:<2>", Test: "com.viant.DummyTest.testSum"},
+			},
+		},
+	}
+	for _, useCase := range useCases {
+		parser, ok := endly.BuildDiagnosticParsers[useCase.Name]
+		if !assert.True(t, ok, useCase.Name) {
+			continue
+		}
+		assert.EqualValues(t, useCase.Expected, parser(useCase.Output), useCase.Name)
+	}
+}
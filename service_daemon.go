@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/lunixbochs/vtclean"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
 	"github.com/viant/toolbox/url"
 	"path"
+	"sort"
 	"strings"
 )
 
@@ -21,6 +23,12 @@ const DaemonServiceStatusAction = "status"
 //DaemonServiceStopAction represents a daemon start action
 const DaemonServiceStopAction = "stop"
 
+//DaemonServiceInstallAction represents a daemon install action
+const DaemonServiceInstallAction = "install"
+
+//DaemonServiceUninstallAction represents a daemon uninstall action
+const DaemonServiceUninstallAction = "uninstall"
+
 const (
 	serviceTypeError = iota
 	serviceTypeInitDaemon
@@ -61,6 +69,19 @@ func (s *daemonService) Run(context *Context, request interface{}) *ServiceRespo
 		if err != nil {
 			response.Error = fmt.Sprintf("failed to check status service: %v, %v", actualRequest.Service, err)
 		}
+	case *DaemonInstallRequest:
+		info, err := s.installService(context, actualRequest)
+		response.Response = info
+		if err != nil {
+			response.Error = fmt.Sprintf("failed to install service: %v, %v", actualRequest.Service, err)
+		} else if info != nil && !info.IsActive() {
+			response.Error = fmt.Sprintf("failed to install service: %v, service is inactive", actualRequest.Service)
+		}
+	case *DaemonUninstallRequest:
+		response.Response, err = s.uninstallService(context, actualRequest)
+		if err != nil {
+			response.Error = fmt.Sprintf("failed to uninstall service: %v, %v", actualRequest.Service, err)
+		}
 	default:
 		response.Error = fmt.Sprintf("unsupported request type: %T", request)
 	}
@@ -78,6 +99,10 @@ func (s *daemonService) NewRequest(action string) (interface{}, error) {
 		return &DaemonStartRequest{}, nil
 	case DaemonServiceStopAction:
 		return &DaemonStopRequest{}, nil
+	case DaemonServiceInstallAction:
+		return &DaemonInstallRequest{}, nil
+	case DaemonServiceUninstallAction:
+		return &DaemonUninstallRequest{}, nil
 	}
 	return s.AbstractService.NewRequest(action)
 }
@@ -439,13 +464,286 @@ func (s *daemonService) startService(context *Context, request *DaemonStartReque
 	})
 }
 
+//unitServiceType returns daemon type used to install or uninstall a unit, running systemd is checked on its own since systemd hosts usually also ship service command matched first by determineServiceType
+func (s *daemonService) unitServiceType(context *Context, service string, target *url.Resource) (int, error) {
+	commandResult, err := context.Execute(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: "ls -d /run/systemd/system",
+			},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var stdout = commandResult.Stdout()
+	if strings.Contains(stdout, "/run/systemd/system") && !CheckNoSuchFileOrDirectory(stdout) {
+		return serviceTypeSystemctl, nil
+	}
+	return s.determineServiceType(context, service, "", target)
+}
+
+//daemonUnit returns service unit file path and its default template for the service type
+func daemonUnit(serviceType int, service string) (string, string, error) {
+	switch serviceType {
+	case serviceTypeSystemctl:
+		return fmt.Sprintf("/etc/systemd/system/%v.service", service), "meta/daemon/systemd.service", nil
+	case serviceTypeLaunchCtl:
+		return fmt.Sprintf("/Library/LaunchDaemons/%v.plist", service), "meta/daemon/launchd.plist", nil
+	}
+	return "", "", fmt.Errorf("unsupported daemon service type, install requires systemd or launchd")
+}
+
+//unitTemplate returns request template or the default one from the workflow repository
+func (s *daemonService) unitTemplate(context *Context, request *DaemonInstallRequest, defaultTemplate string) (*url.Resource, error) {
+	if request.Template != nil {
+		return context.ExpandResource(request.Template)
+	}
+	service, err := context.Service(WorkflowServiceID)
+	if err != nil {
+		return nil, err
+	}
+	workflowService, ok := service.(*workflowService)
+	if !ok {
+		return nil, fmt.Errorf("unsupported workflow service type: %T", service)
+	}
+	return workflowService.Dao.NewRepoResource(context.State(), defaultTemplate)
+}
+
+//systemdEscape escapes systemd specifiers, quoted value also escapes backslashes and double quotes
+func systemdEscape(value string, quoted bool) string {
+	if quoted {
+		value = strings.Replace(value, `\`, `\\`, -1)
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+	return strings.Replace(value, "%", "%%", -1)
+}
+
+//systemdExecStart returns ExecStart command line, arguments with whitespace or quotes are double quoted, variable references are escaped
+func systemdExecStart(arguments []string) string {
+	var result = make([]string, 0)
+	for _, argument := range arguments {
+		argument = strings.Replace(argument, "$", "$$", -1)
+		if argument == "" || strings.ContainsAny(argument, " \t\n\"'\\") {
+			result = append(result, `"`+systemdEscape(argument, true)+`"`)
+			continue
+		}
+		result = append(result, systemdEscape(argument, false))
+	}
+	return strings.Join(result, " ")
+}
+
+//unitData returns expanded request data used to render unit template
+func (s *daemonService) unitData(context *Context, request *DaemonInstallRequest) (data.Map, error) {
+	var result = data.NewMap()
+	var env = make(map[string]string)
+	var environment = make([]string, 0)
+	for key, value := range request.Env {
+		env[key] = context.Expand(value)
+		environment = append(environment, `"`+systemdEscape(key+"="+env[key], true)+`"`)
+	}
+	sort.Strings(environment)
+	var restart = request.Restart
+	if restart == "" {
+		restart = "no"
+	}
+	var command = context.Expand(request.Command)
+	arguments, err := splitShellArguments(command)
+	if err != nil {
+		return nil, err
+	}
+	for _, argument := range request.Arguments {
+		arguments = append(arguments, context.Expand(argument))
+	}
+	result.Put("Service", context.Expand(request.Service))
+	result.Put("Description", context.Expand(request.Description))
+	result.Put("Command", command)
+	result.Put("Arguments", arguments)
+	result.Put("ExecStart", systemdExecStart(arguments))
+	result.Put("Env", env)
+	result.Put("Environment", environment)
+	result.Put("User", context.Expand(request.User))
+	result.Put("Directory", context.Expand(request.Directory))
+	result.Put("Restart", restart)
+	return result, nil
+}
+
+//writeUnit renders unit template into the temp file on the target host and moves it to the unit path as super user
+func (s *daemonService) writeUnit(context *Context, target *url.Resource, request *DaemonInstallRequest, serviceType int, unitPath, defaultTemplate string) error {
+	templateResource, err := s.unitTemplate(context, request, defaultTemplate)
+	if err != nil {
+		return err
+	}
+	unitData, err := s.unitData(context, request)
+	if err != nil {
+		return err
+	}
+	var state = context.State()
+	state.Put("daemon", unitData)
+	defer state.Delete("daemon")
+	var tempPath = path.Join("/tmp", path.Base(unitPath))
+	_, err = context.Transfer(&Transfer{
+		Source:   templateResource,
+		Target:   url.NewResource(fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, tempPath), target.Credential),
+		Template: true,
+	})
+	if err != nil {
+		return err
+	}
+	var owner = "root:root"
+	if serviceType == serviceTypeLaunchCtl {
+		owner = "root:wheel"
+	}
+	_, err = context.ExecuteAsSuperUser(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("mv %v %v", tempPath, unitPath),
+				Error:   []string{"No such file or directory", "Permission denied"},
+			},
+			{
+				Command: fmt.Sprintf("chown %v %v", owner, unitPath),
+				Error:   []string{"No such file or directory", "Operation not permitted"},
+			},
+			{
+				Command: fmt.Sprintf("chmod 644 %v", unitPath),
+				Error:   []string{"No such file or directory", "Operation not permitted"},
+			},
+		},
+	})
+	return err
+}
+
+//installService stops the running service, writes its unit, reloads, enables and starts it
+func (s *daemonService) installService(context *Context, request *DaemonInstallRequest) (*DaemonInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	var service = context.Expand(request.Service)
+	serviceType, err := s.unitServiceType(context, service, target)
+	if err != nil {
+		return nil, err
+	}
+	unitPath, defaultTemplate, err := daemonUnit(serviceType, service)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.stopService(context, &DaemonStopRequest{
+		Target:  request.Target,
+		Service: service,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serviceType == serviceTypeLaunchCtl && info.Launched {
+		if err = s.runCommands(context, serviceType, target, fmt.Sprintf("launchctl unload %v", unitPath)); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.writeUnit(context, target, request, serviceType, unitPath, defaultTemplate); err != nil {
+		return nil, err
+	}
+	if serviceType == serviceTypeSystemctl {
+		if err = s.runCommands(context, serviceType, target, "systemctl daemon-reload", fmt.Sprintf("systemctl enable %v", service)); err != nil {
+			return nil, err
+		}
+	}
+	return s.startService(context, &DaemonStartRequest{
+		Target:  request.Target,
+		Service: service,
+	})
+}
+
+//uninstallService stops and disables the service, removes its unit and reloads daemon configuration
+func (s *daemonService) uninstallService(context *Context, request *DaemonUninstallRequest) (*DaemonInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	var service = context.Expand(request.Service)
+	serviceType, err := s.unitServiceType(context, service, target)
+	if err != nil {
+		return nil, err
+	}
+	unitPath, _, err := daemonUnit(serviceType, service)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.stopService(context, &DaemonStopRequest{
+		Target:  request.Target,
+		Service: service,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var command = fmt.Sprintf("systemctl disable %v", service)
+	if serviceType == serviceTypeLaunchCtl {
+		command = ""
+		if info.Launched {
+			command = fmt.Sprintf("launchctl unload %v", unitPath)
+		}
+	}
+	if err = s.runCommands(context, serviceType, target, command); err != nil {
+		return nil, err
+	}
+	_, err = context.ExecuteAsSuperUser(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("rm -f %v", unitPath),
+				Error:   []string{"Permission denied", "Operation not permitted"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serviceType == serviceTypeSystemctl {
+		if err = s.runCommands(context, serviceType, target, "systemctl daemon-reload"); err != nil {
+			return nil, err
+		}
+	}
+	return s.checkService(context, &DaemonStatusRequest{
+		Target:  request.Target,
+		Service: service,
+	})
+}
+
+//runCommands executes daemon management commands, empty commands are skipped
+func (s *daemonService) runCommands(context *Context, serviceType int, target *url.Resource, commands ...string) error {
+	var executions = make([]*Execution, 0)
+	for _, command := range commands {
+		if command == "" {
+			continue
+		}
+		executions = append(executions, &Execution{
+			Command: command,
+			Error:   []string{commandNotFound, "Failed to"},
+		})
+	}
+	if len(executions) == 0 {
+		return nil
+	}
+	_, err := s.executeCommand(context, serviceType, target, &ExtractableCommand{
+		Executions: executions,
+	})
+	return err
+}
+
 //NewDaemonService creates a new system service.
 func NewDaemonService() Service {
 	var result = &daemonService{
 		AbstractService: NewAbstractService(DaemonServiceID,
 			DaemonServiceStartAction,
 			DaemonServiceStatusAction,
-			DaemonServiceStopAction),
+			DaemonServiceStopAction,
+			DaemonServiceInstallAction,
+			DaemonServiceUninstallAction),
 	}
 	result.AbstractService.Service = result
 	return result
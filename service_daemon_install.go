@@ -0,0 +1,51 @@
+package endly
+
+import (
+	"errors"
+	"fmt"
+	"github.com/viant/toolbox/url"
+)
+
+//DaemonInstallRequest represents a request to install a service as systemd unit or launchd daemon
+type DaemonInstallRequest struct {
+	Target      *url.Resource     //target host
+	Service     string            //service name, unit name for systemd, label for launchd
+	Description string            //service description
+	Command     string            //command optionally followed by shell quoted arguments, systemd requires absolute command path
+	Arguments   []string          //additional command arguments, passed as is
+	Env         map[string]string //service environment variables
+	User        string            //user the service runs as
+	Directory   string            //working directory
+	Restart     string            //restart policy: no, always or on-failure, no by default
+	Template    *url.Resource     //optional unit or plist go text/template, meta/daemon/systemd.service or meta/daemon/launchd.plist by default
+}
+
+//DaemonUninstallRequest represents a request to stop, disable and remove installed service
+type DaemonUninstallRequest struct {
+	Target  *url.Resource //target host
+	Service string        //service name
+}
+
+//Validate checks if request is valid
+func (r *DaemonInstallRequest) Validate() error {
+	if r.Service == "" {
+		return errors.New("service was empty")
+	}
+	if r.Command == "" {
+		return errors.New("command was empty")
+	}
+	switch r.Restart {
+	case "", "no", "always", "on-failure":
+	default:
+		return fmt.Errorf("unsupported restart policy: %v, expected no, always or on-failure", r.Restart)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DaemonUninstallRequest) Validate() error {
+	if r.Service == "" {
+		return errors.New("service was empty")
+	}
+	return nil
+}
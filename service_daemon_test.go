@@ -1,9 +1,14 @@
 package endly_test
 
 import (
+	"bytes"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -273,3 +278,149 @@ func TestDaemonService_Stop(t *testing.T) {
 
 	}
 }
+
+func TestDaemonInstallRequest_Validate(t *testing.T) {
+	assert.NotNil(t, (&endly.DaemonInstallRequest{Command: "/usr/bin/app"}).Validate())
+	assert.NotNil(t, (&endly.DaemonInstallRequest{Service: "app"}).Validate())
+	assert.NotNil(t, (&endly.DaemonInstallRequest{Service: "app", Command: "/usr/bin/app", Restart: "sometimes"}).Validate())
+	assert.Nil(t, (&endly.DaemonInstallRequest{Service: "app", Command: "/usr/bin/app", Restart: "on-failure"}).Validate())
+	assert.NotNil(t, (&endly.DaemonUninstallRequest{}).Validate())
+}
+
+func TestDaemonService_InstallTemplate(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	state.Put("daemon", map[string]interface{}{
+		"Service":     "app",
+		"Command":     "/opt/app/bin/app -port 8080",
+		"Arguments":   []string{"/opt/app/bin/app", "-port", "8080"},
+		"ExecStart":   "/opt/app/bin/app -port 8080",
+		"Env":         map[string]string{"APP_ENV": "test"},
+		"Environment": []string{`"APP_ENV=test"`},
+		"User":        "app",
+		"Directory":   "/opt/app",
+		"Restart":     "always",
+	})
+	var useCases = []struct {
+		template string
+		expected []string
+	}{
+		{
+			"meta/daemon/systemd.service",
+			[]string{"Description=app\n", "ExecStart=/opt/app/bin/app -port 8080\n", "User=app\n", "WorkingDirectory=/opt/app\n", "Environment=\"APP_ENV=test\"\n", "Restart=always\n"},
+		},
+		{
+			"meta/daemon/launchd.plist",
+			[]string{"<string>app</string>", "<string>/opt/app/bin/app</string>\n\t\t<string>-port</string>", "<key>APP_ENV</key>\n\t\t<string>test</string>", "<key>KeepAlive</key>\n\t<true/>"},
+		},
+	}
+	for _, useCase := range useCases {
+		template, err := ioutil.ReadFile(useCase.template)
+		if !assert.Nil(t, err, useCase.template) {
+			continue
+		}
+		memStorage.Upload(url.NewResource(useCase.template).URL, bytes.NewReader(template))
+		var targetURL = "mem:///test/daemon/" + useCase.template
+		_, err = context.Transfer(&endly.Transfer{
+			Source:   url.NewResource(useCase.template),
+			Target:   url.NewResource(targetURL),
+			Template: true,
+		})
+		if !assert.Nil(t, err, useCase.template) {
+			continue
+		}
+		object, err := memStorage.StorageObject(targetURL)
+		if !assert.Nil(t, err, useCase.template) {
+			continue
+		}
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err, useCase.template) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			for _, expected := range useCase.expected {
+				assert.True(t, strings.Contains(string(content), expected), string(content))
+			}
+		}
+	}
+}
+
+func TestDaemonService_Install(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	memStorage := storage.NewMemoryService()
+	template, err := ioutil.ReadFile("meta/daemon/systemd.service")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var templateResource = url.NewResource("meta/daemon/systemd.service")
+	memStorage.Upload(templateResource.URL, bytes.NewReader(template))
+	manager := endly.NewManager()
+	//systemd host shipping service command, status, stop and start use service, unit is installed with systemctl
+	execService, err := GetReplayService("test/daemon/install/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.State().Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.DaemonServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.DaemonInstallRequest{
+		Target:    target,
+		Service:   "myapp",
+		Command:   `/opt/app/bin/app -name "my app"`,
+		Arguments: []string{"-port", "8080"},
+		Env:       map[string]string{"APP_OPTS": `-Dmsg="hi" 100%`},
+		Directory: "/opt/app",
+		Restart:   "always",
+		Template:  templateResource,
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	info, ok := serviceResponse.Response.(*endly.DaemonInfo)
+	if assert.True(t, ok) {
+		assert.True(t, info.IsActive())
+		assert.Equal(t, 5151, info.Pid)
+	}
+	object, err := memStorage.StorageObject("scp://127.0.0.1:22/tmp/myapp.service")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.True(t, strings.Contains(string(content), "ExecStart=/opt/app/bin/app -name \"my app\" -port 8080\n"), string(content))
+			assert.True(t, strings.Contains(string(content), `Environment="APP_OPTS=-Dmsg=\"hi\" 100%%"`+"\n"), string(content))
+			assert.True(t, strings.Contains(string(content), "Restart=always\n"), string(content))
+		}
+	}
+	var executed = make([]string, 0)
+	for _, event := range context.Events.Events {
+		if executionEvent, ok := event.Value["value"].(*endly.ExecutionStartEvent); ok {
+			executed = append(executed, executionEvent.Stdin)
+		}
+	}
+	var commands = strings.Join(executed, "\n")
+	var previous = -1
+	for _, expected := range []string{
+		"sudo mv /tmp/myapp.service /etc/systemd/system/myapp.service",
+		"sudo systemctl daemon-reload",
+		"sudo systemctl enable myapp",
+		"sudo service myapp start",
+	} {
+		index := strings.Index(commands, expected)
+		if assert.True(t, index > previous, expected) {
+			previous = index
+		}
+	}
+}
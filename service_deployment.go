@@ -19,6 +19,12 @@ const DeploymentServiceLoadAction = "load"
 //DeploymentServiceDeployAction represents a deploy deployment instruction action
 const DeploymentServiceDeployAction = "deploy"
 
+//DeploymentServiceUndeployAction represents an undeploy deployment instruction action
+const DeploymentServiceUndeployAction = "undeploy"
+
+//DeploymentServiceRollbackAction represents a rollback deployment instruction action
+const DeploymentServiceRollbackAction = "rollback"
+
 const artifactKey = "artifact"
 const versionKey = "Version"
 
@@ -149,13 +155,67 @@ func (s *deploymentService) updateSessionDeployment(context *Context, target *ur
 	}
 	session.Mutex.Lock()
 	defer session.Mutex.Unlock()
+	if previous, has := session.Deployed[app]; has && previous != version {
+		session.PreviousDeployed[app] = previous
+	}
+	session.Deployed[app] = version
+	return nil
+}
+
+//resetSessionDeployment sets deployed version after rollback or undeploy and clears previous version, empty version removes app from the session
+func (s *deploymentService) resetSessionDeployment(context *Context, target *url.Resource, app, version string) error {
+	session, err := context.TerminalSession(target)
+	if err != nil {
+		return err
+	}
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+	delete(session.PreviousDeployed, app)
+	if version == "" {
+		delete(session.Deployed, app)
+		return nil
+	}
 	session.Deployed[app] = version
 	return nil
 }
 
+//sessionDeployment returns app version deployed in the session and the previously deployed one
+func (s *deploymentService) sessionDeployment(context *Context, target *url.Resource, app string) (deployed, previous string, has bool) {
+	session, err := context.TerminalSession(target)
+	if err != nil {
+		return "", "", false
+	}
+	session.Mutex.RLock()
+	defer session.Mutex.RUnlock()
+	deployed, has = session.Deployed[app]
+	return deployed, session.PreviousDeployed[app], has
+}
+
+//resolveVersion returns requested version, empty or range version is resolved to the highest matching target version or min release version key
+func (s *deploymentService) resolveVersion(requestedVersion string, deploymentTarget *DeploymentTargetMeta) string {
+	if requestedVersion != "" && !isVersionRange(requestedVersion) {
+		return requestedVersion
+	}
+	var candidates = make([]string, 0)
+	if deploymentTarget.Version != "" && !isVersionRange(deploymentTarget.Version) {
+		candidates = append(candidates, deploymentTarget.Version)
+	}
+	for candidate := range deploymentTarget.MinReleaseVersion {
+		candidates = append(candidates, candidate)
+	}
+	var result = ""
+	for _, candidate := range candidates {
+		if MatchVersion(requestedVersion, candidate) && (result == "" || compareVersions(candidate, result) > 0) {
+			result = candidate
+		}
+	}
+	return result
+}
+
 func (s *deploymentService) discoverTransfer(context *Context, request *DeploymentDeployRequest, meta *DeploymentMeta, deploymentTarget *DeploymentTargetMeta) (*Transfer, error) {
 	var state = context.state
-	if meta.Versioning == "" || request.Version == "" {
+	var version = s.resolveVersion(request.Version, deploymentTarget)
+	if meta.Versioning == "" || version == "" {
 		return deploymentTarget.Deployment.Transfer, nil
 	}
 	var transfer = &Transfer{
@@ -169,9 +229,9 @@ func (s *deploymentService) discoverTransfer(context *Context, request *Deployme
 	var artifact = data.NewMap()
 	state.Put(artifactKey, artifact)
 	var versioningFragments = strings.Split(meta.Versioning, ".")
-	var requestedVersionFragment = strings.Split(request.Version, ".")
+	var requestedVersionFragment = strings.Split(version, ".")
 	if len(deploymentTarget.MinReleaseVersion) == 0 || len(versioningFragments) == len(requestedVersionFragment) {
-		artifact.Put(versionKey, request.Version)
+		artifact.Put(versionKey, version)
 		for i, fragmentKey := range versioningFragments {
 			artifact.Put(fragmentKey, requestedVersionFragment[i])
 		}
@@ -185,16 +245,16 @@ func (s *deploymentService) discoverTransfer(context *Context, request *Deployme
 		for i, versionFragment := range requestedVersionFragment {
 			artifact.Put(versioningFragments[i], versionFragment)
 		}
-		minReleaseVersion, has := deploymentTarget.MinReleaseVersion[request.Version]
+		minReleaseVersion, has := deploymentTarget.MinReleaseVersion[version]
 		if !has {
-			return nil, fmt.Errorf("failed to discover source - unable to determine minReleaseVersion for %v", request.Version)
+			return nil, fmt.Errorf("failed to discover source - unable to determine minReleaseVersion for %v", version)
 		}
 		var maxReleaseVersion = strings.Repeat("9", len(minReleaseVersion))
 		var min = toolbox.AsInt(minReleaseVersion)
 		var max = toolbox.AsInt(maxReleaseVersion)
 		for i := min; i <= max; i++ {
 			artifact.Put(releaseFragmentKey, toolbox.AsString(i))
-			artifact.Put(versionKey, fmt.Sprintf("%v.%v", request.Version, i))
+			artifact.Put(versionKey, fmt.Sprintf("%v.%v", version, i))
 			var sourceURL = context.Expand(source.URL)
 			exists, _ := service.Exists(sourceURL)
 			if exists {
@@ -243,9 +303,12 @@ func (s *deploymentService) deploy(context *Context, request *DeploymentDeployRe
 	}
 
 	request = &DeploymentDeployRequest{
-		AppName: context.Expand(request.AppName),
-		Version: context.Expand(request.Version),
-		Target:  request.Target,
+		AppName:  context.Expand(request.AppName),
+		Version:  context.Expand(request.Version),
+		Target:   request.Target,
+		MetaURL:  request.MetaURL,
+		Force:    request.Force,
+		Rollback: request.Rollback,
 	}
 	target, err := context.ExpandResource(request.Target)
 	if err != nil {
@@ -258,8 +321,8 @@ func (s *deploymentService) deploy(context *Context, request *DeploymentDeployRe
 	}
 
 	var response = &DeploymentDeployResponse{}
-	if s.checkIfDeployedOnSession(context, target, request) {
-		response.Version = request.Version
+	if !request.Force && s.checkIfDeployedOnSession(context, target, request) {
+		response.Version, _, _ = s.sessionDeployment(context, target, request.AppName)
 		return response, nil
 	}
 	meta, err := s.getMeta(context, request)
@@ -283,43 +346,188 @@ func (s *deploymentService) deploy(context *Context, request *DeploymentDeployRe
 			return response, err
 		}
 	}
+	var previousVersion string
+	if request.Rollback {
+		previousVersion = s.previousVersion(context, target, deploymentTarget, request.AppName)
+	}
 	transfer, err := s.discoverTransfer(context, request, meta, deploymentTarget)
 	if err != nil {
 		return nil, err
 	}
+	if transfer, err = s.verifiedTransfer(context, deploymentTarget.Deployment, transfer); err != nil {
+		return nil, err
+	}
 	var artifact = state.GetMap(artifactKey)
 	if artifact != nil {
-		response.Version = artifact.GetString("")
+		response.Version = artifact.GetString(versionKey)
 	}
 	defer state.Delete(artifactKey)
-	err = s.deployAddition(context, target, deploymentTarget.Deployment.Pre)
-	if err != nil {
+	if err = s.install(context, target, request, deploymentTarget, transfer); err != nil {
+		if request.Rollback {
+			err = s.rollbackFailed(context, target, request, deploymentTarget, previousVersion, err)
+		}
 		return nil, err
 	}
+	//range or empty requested version is stored as resolved artifact version, so that session checks and rollback use actual version
+	var version = request.Version
+	if (version == "" || isVersionRange(version)) && response.Version != "" {
+		version = response.Version
+	}
+	err = s.updateSessionDeployment(context, target, request.AppName, version)
+	return response, err
+}
+
+//install runs deployment instructions and verifies that app has been deployed
+func (s *deploymentService) install(context *Context, target *url.Resource, request *DeploymentDeployRequest, deploymentTarget *DeploymentTargetMeta, transfer *Transfer) error {
+	err := s.deployAddition(context, target, deploymentTarget.Deployment.Pre)
+	if err != nil {
+		return err
+	}
 	_, err = context.Transfer(transfer)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deploy: %v", err)
+		return fmt.Errorf("failed to deploy: %v", err)
 	}
 	if deploymentTarget.Deployment.Command != nil {
 		_, err = context.Execute(target,
 			deploymentTarget.Deployment.Command,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to init deploy app to %v: %v", target, err)
+			return fmt.Errorf("failed to init deploy app to %v: %v", target, err)
 		}
 	}
-
-	err = s.deployAddition(context, target, deploymentTarget.Deployment.Post)
+	if err = s.deployAddition(context, target, deploymentTarget.Deployment.Post); err != nil {
+		return err
+	}
 	if deployed, _ := s.checkIfDeployedOnSystem(context, target, deploymentTarget, request); deployed {
-		var version = request.Version
-		if version == "" {
-			version = response.Version
-		}
-		err = s.updateSessionDeployment(context, target, request.AppName, version)
-		return response, err
+		return nil
+	}
+	return fmt.Errorf("failed to deploy %v, unable to verify deployments", request.AppName)
+}
+
+//previousVersion returns version deployed in the session or detected on the system with version check
+func (s *deploymentService) previousVersion(context *Context, target *url.Resource, deploymentTarget *DeploymentTargetMeta, app string) string {
+	if deployed, _, has := s.sessionDeployment(context, target, app); has {
+		return deployed
+	}
+	version, _ := s.extractVersion(context, target, deploymentTarget.Deployment)
+	return version
+}
+
+//rollbackFailed rolls back failed deployment, returned error reports both deployment error and rollback outcome,
+//previous version is redeployed even if it is the same as the failed one, i.e. failed forced redeployment, the app is uninstalled only if there was no previous version
+func (s *deploymentService) rollbackFailed(context *Context, target *url.Resource, request *DeploymentDeployRequest, deploymentTarget *DeploymentTargetMeta, previousVersion string, deployError error) error {
+	restored, err := s.restore(context, target, request.MetaURL, request.AppName, deploymentTarget, request.Version, previousVersion)
+	if err != nil {
+		return fmt.Errorf("%v, failed to roll back: %v", deployError, err)
+	}
+	if err = s.resetSessionDeployment(context, target, request.AppName, restored); err != nil {
+		return err
+	}
+	if restored == "" {
+		return fmt.Errorf("%v, uninstalled %v", deployError, request.AppName)
 	}
+	return fmt.Errorf("%v, rolled back to %v", deployError, restored)
+}
 
-	return nil, fmt.Errorf("failed to deploy %v, unable to verify deployments", request.AppName)
+func (s *deploymentService) putArtifactVersion(context *Context, version, previousVersion string) {
+	var artifact = data.NewMap()
+	artifact.Put(versionKey, version)
+	artifact.Put("PreviousVersion", previousVersion)
+	context.state.Put(artifactKey, artifact)
+}
+
+//restore runs deployment target rollback instruction if specified, otherwise redeploys previous version, if there is no previous version it uninstalls the app, it returns restored version
+func (s *deploymentService) restore(context *Context, target *url.Resource, metaURL, app string, deploymentTarget *DeploymentTargetMeta, version, previousVersion string) (string, error) {
+	if deploymentTarget.Rollback != nil {
+		s.putArtifactVersion(context, version, previousVersion)
+		defer context.state.Delete(artifactKey)
+		return previousVersion, s.deployAddition(context, target, deploymentTarget.Rollback)
+	}
+	if previousVersion != "" {
+		_, err := s.deploy(context, &DeploymentDeployRequest{
+			Target:  target,
+			MetaURL: metaURL,
+			AppName: app,
+			Version: previousVersion,
+			Force:   true,
+		})
+		return previousVersion, err
+	}
+	if deploymentTarget.Uninstall != nil {
+		return "", s.uninstall(context, target, deploymentTarget, version)
+	}
+	return "", fmt.Errorf("no previous %v version, rollback nor uninstall instruction", app)
+}
+
+func (s *deploymentService) uninstall(context *Context, target *url.Resource, deploymentTarget *DeploymentTargetMeta, version string) error {
+	s.putArtifactVersion(context, version, "")
+	defer context.state.Delete(artifactKey)
+	return s.deployAddition(context, target, deploymentTarget.Uninstall)
+}
+
+func (s *deploymentService) undeploy(context *Context, request *DeploymentUndeployRequest) (*DeploymentUndeployResponse, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	var app = context.Expand(request.AppName)
+	var version = context.Expand(request.Version)
+	if version == "" {
+		version, _, _ = s.sessionDeployment(context, target, app)
+	}
+	meta, err := s.getMeta(context, &DeploymentDeployRequest{AppName: app, MetaURL: request.MetaURL})
+	if err != nil {
+		return nil, err
+	}
+	deploymentTarget, err := s.matchDeployment(context, version, target, meta)
+	if err != nil {
+		return nil, err
+	}
+	if deploymentTarget.Uninstall == nil {
+		return nil, fmt.Errorf("uninstall instruction was empty")
+	}
+	s.updateOperatingSystem(context, target)
+	if err = s.uninstall(context, target, deploymentTarget, version); err != nil {
+		return nil, err
+	}
+	return &DeploymentUndeployResponse{Version: version}, s.resetSessionDeployment(context, target, app, "")
+}
+
+func (s *deploymentService) rollback(context *Context, request *DeploymentRollbackRequest) (*DeploymentRollbackResponse, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	var app = context.Expand(request.AppName)
+	deployed, previous, has := s.sessionDeployment(context, target, app)
+	if !has {
+		return nil, fmt.Errorf("%v has not been deployed to %v", app, target.Host())
+	}
+	if version := context.Expand(request.Version); version != "" {
+		previous = version
+	}
+	meta, err := s.getMeta(context, &DeploymentDeployRequest{AppName: app, MetaURL: request.MetaURL})
+	if err != nil {
+		return nil, err
+	}
+	deploymentTarget, err := s.matchDeployment(context, deployed, target, meta)
+	if err != nil {
+		return nil, err
+	}
+	s.updateOperatingSystem(context, target)
+	restored, err := s.restore(context, target, request.MetaURL, app, deploymentTarget, deployed, previous)
+	if err != nil {
+		return nil, err
+	}
+	return &DeploymentRollbackResponse{RolledBack: deployed, Version: restored}, s.resetSessionDeployment(context, target, app, restored)
 }
 
 func (s *deploymentService) Run(context *Context, request interface{}) *ServiceResponse {
@@ -333,6 +541,18 @@ func (s *deploymentService) Run(context *Context, request interface{}) *ServiceR
 		if err != nil {
 			response.Error = fmt.Sprintf("failed to run deployment: %v, %v", castedRequest.AppName, err)
 		}
+	case *DeploymentUndeployRequest:
+		var err error
+		response.Response, err = s.undeploy(context, castedRequest)
+		if err != nil {
+			response.Error = fmt.Sprintf("failed to undeploy: %v, %v", castedRequest.AppName, err)
+		}
+	case *DeploymentRollbackRequest:
+		var err error
+		response.Response, err = s.rollback(context, castedRequest)
+		if err != nil {
+			response.Error = fmt.Sprintf("failed to roll back: %v, %v", castedRequest.AppName, err)
+		}
 	case *DeploymentMetaRequest:
 		var err error
 		response.Response, err = s.loadMeta(context, castedRequest)
@@ -412,6 +632,10 @@ func (s *deploymentService) NewRequest(action string) (interface{}, error) {
 	switch action {
 	case DeploymentServiceDeployAction:
 		return &DeploymentDeployRequest{}, nil
+	case DeploymentServiceUndeployAction:
+		return &DeploymentUndeployRequest{}, nil
+	case DeploymentServiceRollbackAction:
+		return &DeploymentRollbackRequest{}, nil
 	case DeploymentServiceLoadAction:
 		return &DeploymentMetaRequest{}, nil
 	}
@@ -423,6 +647,8 @@ func NewDeploymentService() Service {
 	var result = &deploymentService{
 		AbstractService: NewAbstractService(DeploymentServiceID,
 			DeploymentServiceDeployAction,
+			DeploymentServiceUndeployAction,
+			DeploymentServiceRollbackAction,
 			DeploymentServiceLoadAction),
 		mutex:    &sync.RWMutex{},
 		registry: make(map[string]*DeploymentMeta),
@@ -0,0 +1,201 @@
+package endly
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"golang.org/x/crypto/openpgp"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+//DeploymentCacheDirectory represents local directory caching verified downloads under <sha256>/<file name>
+var DeploymentCacheDirectory = path.Join(os.Getenv("HOME"), ".endly", "cache", "deployment")
+
+var sha256DigestExpr = regexp.MustCompile(`\b[a-fA-F0-9]{64}\b`)
+
+//DeploymentChecksum represents expected sha256 checksum of the deployment transfer source
+type DeploymentChecksum struct {
+	SHA256 string        //inline sha256 hex digest
+	URL    *url.Resource //checksum file URL, i.e. sha256sum output, the digest on the line with the source file name is used
+}
+
+//DeploymentSignature represents detached signature of the deployment transfer source
+type DeploymentSignature struct {
+	URL     *url.Resource //detached signature URL, armored or binary
+	Keyring *url.Resource //public keyring URL, armored or binary
+}
+
+//Validate checks if checksum is valid
+func (c *DeploymentChecksum) Validate() error {
+	if c.SHA256 == "" && (c.URL == nil || c.URL.URL == "") {
+		return fmt.Errorf("checksum SHA256 and URL were empty")
+	}
+	return nil
+}
+
+//Validate checks if signature is valid
+func (s *DeploymentSignature) Validate() error {
+	if s.URL == nil || s.URL.URL == "" {
+		return fmt.Errorf("signature URL was empty")
+	}
+	if s.Keyring == nil || s.Keyring.URL == "" {
+		return fmt.Errorf("signature keyring was empty")
+	}
+	return nil
+}
+
+//expectedChecksum returns inline checksum or the one from checksum file matching source file name
+func (s *deploymentService) expectedChecksum(context *Context, checksum *DeploymentChecksum, fileName string) (string, error) {
+	if checksum.SHA256 != "" {
+		return strings.ToLower(context.Expand(checksum.SHA256)), nil
+	}
+	content, err := downloadContent(context, checksum.URL)
+	if err != nil {
+		return "", err
+	}
+	var digests = sha256DigestExpr.FindAllString(string(content), -1)
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, fileName) {
+			if digest := sha256DigestExpr.FindString(line); digest != "" {
+				return strings.ToLower(digest), nil
+			}
+		}
+	}
+	if len(digests) == 1 {
+		return strings.ToLower(digests[0]), nil
+	}
+	return "", fmt.Errorf("failed to lookup %v sha256 checksum in %v", fileName, context.Expand(checksum.URL.URL))
+}
+
+//download copies source into the file, it returns sha256 digest of the downloaded content
+func (s *deploymentService) download(context *Context, source *url.Resource, filename string) (string, error) {
+	service, err := getStorageService(context, source)
+	if err != nil {
+		return "", err
+	}
+	defer service.Close()
+	object, err := service.StorageObject(source.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v, %v", source.URL, err)
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v, %v", source.URL, err)
+	}
+	defer reader.Close()
+	if err = os.MkdirAll(path.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var hash = sha256.New()
+	if _, err = io.Copy(io.MultiWriter(file, hash), reader); err != nil {
+		return "", fmt.Errorf("failed to download %v, %v", source.URL, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+//fileChecksum returns sha256 digest of the local file
+func fileChecksum(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var hash = sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+//verifySignature checks file detached signature with the keyring
+func (s *deploymentService) verifySignature(context *Context, signature *DeploymentSignature, filename string) error {
+	keyringContent, err := downloadContent(context, signature.Keyring)
+	if err != nil {
+		return err
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyringContent))
+	if err != nil {
+		if keyring, err = openpgp.ReadKeyRing(bytes.NewReader(keyringContent)); err != nil {
+			return fmt.Errorf("failed to read keyring %v, %v", context.Expand(signature.Keyring.URL), err)
+		}
+	}
+	signatureContent, err := downloadContent(context, signature.URL)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if bytes.Contains(signatureContent, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, file, bytes.NewReader(signatureContent))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, file, bytes.NewReader(signatureContent))
+	}
+	return err
+}
+
+//verifiedTransfer downloads transfer source into the local cache and verifies its checksum and signature, returned transfer uses the cached file as source, cached file with matching checksum is not downloaded again,
+//signature only deployment is downloaded every time since cache lookup needs digest known upfront, downloaded file is still cached under its actual digest
+func (s *deploymentService) verifiedTransfer(context *Context, deployment *Deployment, transfer *Transfer) (*Transfer, error) {
+	if deployment.Checksum == nil && deployment.Signature == nil {
+		return transfer, nil
+	}
+	source, err := context.ExpandResource(transfer.Source)
+	if err != nil {
+		return nil, err
+	}
+	var fileName = path.Base(source.ParsedURL.Path)
+	var expected string
+	if deployment.Checksum != nil {
+		if expected, err = s.expectedChecksum(context, deployment.Checksum, fileName); err != nil {
+			return nil, err
+		}
+	}
+	var filename = path.Join(DeploymentCacheDirectory, expected, fileName)
+	var cached = false
+	if expected != "" {
+		if actual, err := fileChecksum(filename); err == nil && actual == expected {
+			cached = true
+		}
+	}
+	if !cached {
+		var downloadFilename = filename + ".download"
+		defer os.Remove(downloadFilename)
+		actual, err := s.download(context, source, downloadFilename)
+		if err != nil {
+			return nil, err
+		}
+		if expected != "" && actual != expected {
+			return nil, fmt.Errorf("checksum mismatch for %v: expected sha256 %v, but had %v", source.URL, expected, actual)
+		}
+		filename = path.Join(DeploymentCacheDirectory, actual, fileName)
+		if err = os.MkdirAll(path.Dir(filename), 0755); err != nil {
+			return nil, err
+		}
+		if err = os.Rename(downloadFilename, filename); err != nil {
+			return nil, err
+		}
+	}
+	if deployment.Signature != nil {
+		if err = s.verifySignature(context, deployment.Signature, filename); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %v: %v", source.URL, err)
+		}
+	}
+	var result = *transfer
+	result.Source = url.NewResource(toolbox.FileSchema + filename)
+	return &result, nil
+}
@@ -7,11 +7,12 @@ import (
 
 //DeploymentDeployRequest represent a deploy request
 type DeploymentDeployRequest struct {
-	Target  *url.Resource
-	MetaURL string //deployment URL for meta deployment instruction
-	AppName string //app name
-	Version string //requested version
-	Force   bool   //flag force deployment, by default if requested version (Transfer.Target.Version is the one from command version check. deployment is skipped.
+	Target   *url.Resource
+	MetaURL  string //deployment URL for meta deployment instruction
+	AppName  string //app name
+	Version  string //requested version
+	Force    bool   //flag force deployment, by default if requested version (Transfer.Target.Version is the one from command version check. deployment is skipped.
+	Rollback bool   //flag to roll back failed deployment to previously deployed version, or uninstall it if there is none
 }
 
 //Validate check if request is valid otherwise returns error.
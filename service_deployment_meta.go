@@ -3,6 +3,7 @@ package endly
 import (
 	"github.com/pkg/errors"
 	"github.com/viant/toolbox/url"
+	"strings"
 )
 
 //DeploymentMetaRequest represents DeploymentMeta register request.
@@ -29,15 +30,19 @@ type DeploymentTargetMeta struct {
 	OsTarget          *OperatingSystemTarget  //if specified matches current os
 	Deployment        *Deployment             //actual deployment instruction
 	Dependencies      []*DeploymentDependency //app dependencies like sdk
+	Uninstall         *DeploymentAddition     //instruction removing deployed app, ${artifact.Version} holds uninstalled version
+	Rollback          *DeploymentAddition     //instruction restoring previous version, ${artifact.Version} holds rolled back version, ${artifact.PreviousVersion} restored one
 }
 
 //Deployment represents deployment instruction
 type Deployment struct {
 	Pre          *DeploymentAddition
-	Transfer     *Transfer           //actual copy instruction
-	Command      *ExtractableCommand //post deployment command like tar xvzf
-	VersionCheck *ExtractableCommand //command to check version
-	Post         *DeploymentAddition //post deployment
+	Transfer     *Transfer            //actual copy instruction
+	Command      *ExtractableCommand  //post deployment command like tar xvzf
+	VersionCheck *ExtractableCommand  //command to check version
+	Post         *DeploymentAddition  //post deployment
+	Checksum     *DeploymentChecksum  //expected transfer source sha256 checksum, verified source is cached locally
+	Signature    *DeploymentSignature //transfer source detached signature, without checksum source is downloaded on each deployment
 }
 
 //DeploymentAddition represents deployment additions.
@@ -65,6 +70,14 @@ func (d *Deployment) Validate() error {
 	if d.Transfer.Source.URL == "" {
 		return errors.New("Transfer.Source.URL was empty")
 	}
+	if d.Checksum != nil {
+		if err := d.Checksum.Validate(); err != nil {
+			return err
+		}
+	}
+	if d.Signature != nil {
+		return d.Signature.Validate()
+	}
 	return nil
 
 }
@@ -94,36 +107,40 @@ func (a *DeploymentAddition) AsCommandRequest() *CommandRequest {
 	}
 }
 
-//MatchVersion checks expected and actual version returns true if matches.
+//MatchVersion checks expected and actual version returns true if matches, expected version can be a semantic version range, i.e. ^1.2, ~1.2.3, >=11 <12, otherwise only common version fragments are compared.
 func MatchVersion(expected, actual string) bool {
-	var expectedLength = len(expected)
-	var actualLength = len(actual)
-	if expectedLength == 0 || actualLength == 0 {
+	expected = strings.TrimSpace(expected)
+	actual = strings.TrimSpace(actual)
+	if expected == "" || actual == "" {
 		return true
 	}
-
-	if actualLength == expectedLength {
+	if isVersionRange(actual) && !isVersionRange(expected) {
+		expected, actual = actual, expected
+	} else if isVersionRange(actual) {
 		return expected == actual
 	}
-	if actualLength > expectedLength {
-		actual = string(actual[:expectedLength])
-	} else {
-		expected = string(expected[:actualLength])
+	constraint, err := parseVersionConstraint(expected)
+	if err != nil {
+		return expected == actual
 	}
-	return expected == actual
+	return constraint.matches(parseVersion(actual))
 }
 
-//Match provides build instruction for matching os and version
+//Match provides build instruction for matching os and version, if more targets match the one with the highest version is returned
 func (m *DeploymentMeta) Match(operatingSystem *OperatingSystem, requestedVersion string) *DeploymentTargetMeta {
+	var result *DeploymentTargetMeta
 	for _, candidate := range m.Targets {
 		if candidate.Version != "" {
 			if !MatchVersion(requestedVersion, candidate.Version) {
 				continue
 			}
 		}
-		if operatingSystem.Matches(candidate.OsTarget) {
-			return candidate
+		if !operatingSystem.Matches(candidate.OsTarget) {
+			continue
+		}
+		if result == nil || compareVersions(candidate.Version, result.Version) > 0 {
+			result = candidate
 		}
 	}
-	return nil
+	return result
 }
@@ -0,0 +1,28 @@
+package endly
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/toolbox/url"
+)
+
+//DeploymentRollbackRequest represents a rollback request, it restores previously deployed version with rollback instruction or redeployment, if there is no previous version the app is uninstalled
+type DeploymentRollbackRequest struct {
+	Target  *url.Resource
+	MetaURL string //deployment URL for meta deployment instruction
+	AppName string //app name
+	Version string //version to restore, previously deployed version by default
+}
+
+//Validate check if request is valid otherwise returns error.
+func (r *DeploymentRollbackRequest) Validate() error {
+	if r.AppName == "" {
+		return errors.New("App name was empty")
+	}
+	return nil
+}
+
+//DeploymentRollbackResponse represents a rollback response.
+type DeploymentRollbackResponse struct {
+	RolledBack string //rolled back version
+	Version    string //restored version, empty if app was uninstalled
+}
@@ -1,9 +1,16 @@
 package endly_test
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
 	"testing"
 )
 
@@ -14,7 +21,66 @@ func Test_MatchVersion(t *testing.T) {
 	assert.False(t, endly.MatchVersion("10.1", "10.2.1"))
 
 	assert.True(t, endly.MatchVersion("10.2.1", "10.2.1"))
+	assert.False(t, endly.MatchVersion("1.1", "1.10"))
 
+	assert.True(t, endly.MatchVersion("^1.2", "1.9.3"))
+	assert.False(t, endly.MatchVersion("^1.2", "2.0.0"))
+	assert.False(t, endly.MatchVersion("^0.2.3", "0.3.0"))
+	assert.True(t, endly.MatchVersion("~1.2.3", "1.2.9"))
+	assert.False(t, endly.MatchVersion("~1.2.3", "1.3.0"))
+	assert.True(t, endly.MatchVersion(">=11 <12", "11.0.2"))
+	assert.False(t, endly.MatchVersion(">=11 <12", "12.0.1"))
+	assert.True(t, endly.MatchVersion("1.x || 3.x", "v3.1.0"))
+	assert.False(t, endly.MatchVersion("1.x || 3.x", "2.0"))
+	assert.True(t, endly.MatchVersion("1.2 - 1.4", "1.4.7"))
+	assert.True(t, endly.MatchVersion("1.8", "1.8.0_151"))
+	assert.True(t, endly.MatchVersion("10.15.0", ">=10"))
+}
+
+func TestDeploymentMeta_Match(t *testing.T) {
+	var meta = &endly.DeploymentMeta{
+		Name: "app",
+		Targets: []*endly.DeploymentTargetMeta{
+			{Version: "10.15.0"},
+			{Version: "11.6.0", OsTarget: &endly.OperatingSystemTarget{System: "linux", Architecture: "amd64"}},
+			{Version: "11.2.0"},
+			{Version: "8.15.0"},
+		},
+	}
+	var linux = &endly.OperatingSystem{System: "linux", Architecture: "amd64"}
+	var darwin = &endly.OperatingSystem{System: "darwin", Architecture: "amd64"}
+	assert.Equal(t, "11.6.0", meta.Match(linux, "").Version)
+	assert.Equal(t, "11.2.0", meta.Match(darwin, "").Version)
+	assert.Equal(t, "11.6.0", meta.Match(linux, ">=11 <12").Version)
+	assert.Equal(t, "10.15.0", meta.Match(linux, "^10").Version)
+	assert.Equal(t, "8.15.0", meta.Match(darwin, "8").Version)
+	assert.Nil(t, meta.Match(darwin, "^12"))
+}
+
+func TestDeploymentService_LoadMeta(t *testing.T) {
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.DeploymentServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, name := range []string{"node", "python", "chromedriver", "redis", "postgres-client", "kafka"} {
+		serviceResponse := service.Run(context, &endly.DeploymentMetaRequest{
+			Source: url.NewResource(fmt.Sprintf("meta/deployment/%v.json", name)),
+		})
+		if !assert.Equal(t, "", serviceResponse.Error, name) {
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.DeploymentMetaResponse)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, name, response.Meta.Name)
+			for _, target := range response.Meta.Targets {
+				assert.NotNil(t, target.Deployment.VersionCheck, name)
+				assert.NotNil(t, target.Uninstall, name)
+			}
+		}
+	}
 }
 
 func Test_DeplymentValiate(t *testing.T) {
@@ -62,5 +128,233 @@ func Test_DeplymentValiate(t *testing.T) {
 		err := deployment.Validate()
 		assert.NotNil(t, err)
 	}
+	{
+		deployment := &endly.Deployment{
+			Transfer: &endly.Transfer{
+				Target: &url.Resource{URL: "mem:///123"},
+				Source: &url.Resource{URL: "mem:///abc.tar.gz"},
+			},
+			Checksum: &endly.DeploymentChecksum{},
+		}
+		assert.NotNil(t, deployment.Validate())
+		deployment.Checksum.SHA256 = "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+		assert.Nil(t, deployment.Validate())
+		deployment.Signature = &endly.DeploymentSignature{URL: &url.Resource{URL: "mem:///abc.tar.gz.asc"}}
+		assert.NotNil(t, deployment.Validate())
+		deployment.Signature.Keyring = &url.Resource{URL: "mem:///KEYS"}
+		assert.Nil(t, deployment.Validate())
+	}
+
+}
 
+func TestDeploymentService_Rollback(t *testing.T) {
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.DeploymentServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	{
+		response := service.Run(context, &endly.DeploymentRollbackRequest{
+			Target: url.NewResource("scp://127.0.0.1/"),
+		})
+		assert.Equal(t, "error", response.Status)
+	}
+	{
+		response := service.Run(context, &endly.DeploymentRollbackRequest{
+			Target:  url.NewResource("scp://127.0.0.1/"),
+			AppName: "tomcat",
+		})
+		assert.Equal(t, "error", response.Status)
+		assert.Contains(t, response.Error, "has not been deployed")
+	}
+	for _, action := range []string{endly.DeploymentServiceUndeployAction, endly.DeploymentServiceRollbackAction} {
+		request, err := service.NewRequest(action)
+		assert.Nil(t, err)
+		assert.NotNil(t, request)
+	}
+}
+
+func TestDeploymentService_RollbackDeployment(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	for _, version := range []string{"1.0", "1.1", "1.2"} {
+		memStorage.Upload(fmt.Sprintf("mem:///releases/app-%v.tar.gz", version), strings.NewReader("app "+version))
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/deployment/rollback/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.State().Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.DeploymentServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.DeploymentMetaRequest{Source: url.NewResource("test/deployment/rollback/meta.json")})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	session, err := context.TerminalSession(target)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var deployed = func() string {
+		object, err := memStorage.StorageObject("scp://127.0.0.1:22/opt/app/app.tar.gz")
+		if err != nil {
+			return ""
+		}
+		reader, err := memStorage.Download(object)
+		if err != nil {
+			return ""
+		}
+		defer reader.Close()
+		content, _ := ioutil.ReadAll(reader)
+		return string(content)
+	}
+	var deploy = func(version string) *endly.ServiceResponse {
+		return service.Run(context, &endly.DeploymentDeployRequest{Target: target, AppName: "rollbackapp", Version: version, Rollback: true})
+	}
+
+	serviceResponse = deploy("1.0")
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	assert.Equal(t, "app 1.0", deployed())
+	assert.Equal(t, "1.0", session.Deployed["rollbackapp"])
+
+	//failed post deployment check redeploys the previous version
+	serviceResponse = deploy("1.1")
+	assert.Contains(t, serviceResponse.Error, "rolled back to 1.0")
+	assert.Equal(t, "app 1.0", deployed())
+	assert.Equal(t, "1.0", session.Deployed["rollbackapp"])
+	assert.Equal(t, "", session.PreviousDeployed["rollbackapp"])
+
+	//successful deployment keeps track of the replaced version
+	serviceResponse = service.Run(context, &endly.DeploymentDeployRequest{Target: target, AppName: "rollbackapp", Version: "1.2"})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	assert.Equal(t, "app 1.2", deployed())
+	assert.Equal(t, "1.2", session.Deployed["rollbackapp"])
+	assert.Equal(t, "1.0", session.PreviousDeployed["rollbackapp"])
+
+	//rollback redeploys the previous version and clears it
+	serviceResponse = service.Run(context, &endly.DeploymentRollbackRequest{Target: target, AppName: "rollbackapp"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DeploymentRollbackResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, "1.2", response.RolledBack)
+			assert.Equal(t, "1.0", response.Version)
+		}
+	}
+	assert.Equal(t, "app 1.0", deployed())
+	assert.Equal(t, "1.0", session.Deployed["rollbackapp"])
+	assert.Equal(t, "", session.PreviousDeployed["rollbackapp"])
+
+	//undeploy uninstalls the session version
+	serviceResponse = service.Run(context, &endly.DeploymentUndeployRequest{Target: target, AppName: "rollbackapp"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DeploymentUndeployResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, "1.0", response.Version)
+		}
+	}
+	_, has := session.Deployed["rollbackapp"]
+	assert.False(t, has)
+
+	//failed deployment without previous version is uninstalled
+	serviceResponse = deploy("1.1")
+	assert.Contains(t, serviceResponse.Error, "uninstalled rollbackapp")
+	_, has = session.Deployed["rollbackapp"]
+	assert.False(t, has)
+	var uninstalled = 0
+	for _, event := range context.Events.Events {
+		if executionEvent, ok := event.Value["value"].(*endly.ExecutionStartEvent); ok && executionEvent.Stdin == "rm -rf /opt/app" {
+			uninstalled++
+		}
+	}
+	assert.Equal(t, 2, uninstalled)
+}
+
+func TestDeploymentService_VerifiedTransfer(t *testing.T) {
+	cacheDirectory, err := ioutil.TempDir("", "deployment-cache")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(cacheDirectory)
+	defaultCacheDirectory := endly.DeploymentCacheDirectory
+	endly.DeploymentCacheDirectory = cacheDirectory
+	defer func() { endly.DeploymentCacheDirectory = defaultCacheDirectory }()
+
+	var deployedFile = "/tmp/endly/deployment/checksum/app.tar.gz"
+	os.Remove(deployedFile)
+	if !assert.Nil(t, os.MkdirAll(path.Dir(deployedFile), 0755)) {
+		return
+	}
+	var sourceURL = "mem:///releases/checksumapp.tar.gz"
+	var checksum = "ddb90abc5b61b339e953e3ac6c4340f2f3cebef9e8c116becb8f2ec73175c07e"
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload(sourceURL, strings.NewReader("checksum app 1.0"))
+
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/deployment/checksum/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	service, err := context.Service(endly.DeploymentServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.DeploymentMetaRequest{Source: url.NewResource("test/deployment/checksum/meta.json")})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	var deploy = func() *endly.ServiceResponse {
+		return service.Run(context, &endly.DeploymentDeployRequest{Target: target, AppName: "checksumapp", Force: true})
+	}
+
+	//checksum mismatch fails before anything is transferred
+	context.State().Put("appChecksum", strings.Repeat("0", 64))
+	serviceResponse = deploy()
+	assert.Contains(t, serviceResponse.Error, "checksum mismatch for "+sourceURL)
+	assert.False(t, toolbox.FileExists(deployedFile))
+
+	context.State().Put("appChecksum", checksum)
+	serviceResponse = deploy()
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	content, err := ioutil.ReadFile(deployedFile)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "checksum app 1.0", string(content))
+	}
+	assert.True(t, toolbox.FileExists(path.Join(cacheDirectory, checksum, "checksumapp.tar.gz")))
+
+	//second deployment uses the cached file, removed source is not downloaded again
+	object, err := memStorage.StorageObject(sourceURL)
+	if assert.Nil(t, err) {
+		assert.Nil(t, memStorage.Delete(object))
+	}
+	os.Remove(deployedFile)
+	serviceResponse = deploy()
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	content, err = ioutil.ReadFile(deployedFile)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "checksum app 1.0", string(content))
+	}
 }
@@ -0,0 +1,27 @@
+package endly
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/toolbox/url"
+)
+
+//DeploymentUndeployRequest represents an undeploy request, it runs matching deployment target uninstall instruction
+type DeploymentUndeployRequest struct {
+	Target  *url.Resource
+	MetaURL string //deployment URL for meta deployment instruction
+	AppName string //app name
+	Version string //version to uninstall, version deployed in the session by default
+}
+
+//Validate check if request is valid otherwise returns error.
+func (r *DeploymentUndeployRequest) Validate() error {
+	if r.AppName == "" {
+		return errors.New("App name was empty")
+	}
+	return nil
+}
+
+//DeploymentUndeployResponse represents an undeploy response.
+type DeploymentUndeployResponse struct {
+	Version string //uninstalled version
+}
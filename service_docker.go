@@ -1,11 +1,24 @@
 package endly
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/lunixbochs/vtclean"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/cred"
+	"github.com/viant/toolbox/data"
 	"github.com/viant/toolbox/url"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -42,6 +55,68 @@ const (
 	//DockerServiceContainerRemoveAction represents docker container-remove action
 	DockerServiceContainerRemoveAction = "container-remove"
 
+	//DockerServiceComposeUpAction represents docker compose-up action
+	DockerServiceComposeUpAction = "compose-up"
+
+	//DockerServiceComposeDownAction represents docker compose-down action
+	DockerServiceComposeDownAction = "compose-down"
+
+	//DockerServiceComposePsAction represents docker compose-ps action
+	DockerServiceComposePsAction = "compose-ps"
+
+	//DockerServiceComposeLogsAction represents docker compose-logs action
+	DockerServiceComposeLogsAction = "compose-logs"
+
+	//DockerServiceBuildAction represents docker build action
+	DockerServiceBuildAction = "build"
+
+	//DockerServiceTagAction represents docker tag action
+	DockerServiceTagAction = "tag"
+
+	//DockerServicePushAction represents docker push action
+	DockerServicePushAction = "push"
+
+	//DockerServiceLoginAction represents docker login action
+	DockerServiceLoginAction = "login"
+
+	//DockerServiceNetworkCreateAction represents docker network-create action
+	DockerServiceNetworkCreateAction = "network-create"
+
+	//DockerServiceNetworkRemoveAction represents docker network-remove action
+	DockerServiceNetworkRemoveAction = "network-remove"
+
+	//DockerServiceVolumeCreateAction represents docker volume-create action
+	DockerServiceVolumeCreateAction = "volume-create"
+
+	//DockerServiceVolumeRemoveAction represents docker volume-remove action
+	DockerServiceVolumeRemoveAction = "volume-remove"
+
+	//DockerServiceCleanupAction represents docker cleanup action
+	DockerServiceCleanupAction = "cleanup"
+
+	//DockerServiceLogsAction represents docker logs action
+	DockerServiceLogsAction = "logs"
+
+	//DockerServiceCaptureLogsAction represents docker capture-logs action
+	DockerServiceCaptureLogsAction = "capture-logs"
+
+	//DockerComposeBaseDirectory represents a base directory on the docker host where expanded compose files are placed
+	DockerComposeBaseDirectory = "/tmp/endly/compose"
+
+	//DockerComposeLabelsFile represents compose override file name labeling compose resources with the endly session id
+	DockerComposeLabelsFile = "docker-compose.endly.json"
+
+	//DockerBuildBaseDirectory represents a base directory on the docker host where expanded Dockerfiles are placed
+	DockerBuildBaseDirectory = "/tmp/endly/build"
+
+	//DockerBuildLogFrequency represents frequency of reading build log to emit build step events while the build is running
+	DockerBuildLogFrequency = 2 * time.Second
+
+	dockerRegistryCredentialKey = "**docker**"
+
+	//DockerSessionLabel represents a label holding endly session id, it is added to containers, networks and volumes created by docker service
+	DockerSessionLabel = "endly.session"
+
 	containerInUse    = "is already in use by container"
 	unableToFindImage = "unable to find image"
 	dockerError       = "Error response"
@@ -49,6 +124,8 @@ const (
 
 var dockerErrors = []string{"failed", unableToFindImage}
 var dockerIgnoreErrors = []string{}
+var dockerBuildErrors = []string{"returned a non-zero code", "executor failed", unableToFindImage}
+var dockerRegistryErrors = []string{"denied:", "unauthorized:", "not found"}
 
 type dockerService struct {
 	*AbstractService
@@ -77,6 +154,36 @@ func (s *dockerService) NewRequest(action string) (interface{}, error) {
 		return &DockerContainerStatusRequest{}, nil
 	case DockerServiceContainerRemoveAction:
 		return &DockerContainerRemoveRequest{}, nil
+	case DockerServiceComposeUpAction:
+		return &DockerComposeUpRequest{}, nil
+	case DockerServiceComposeDownAction:
+		return &DockerComposeDownRequest{}, nil
+	case DockerServiceComposePsAction:
+		return &DockerComposePsRequest{}, nil
+	case DockerServiceComposeLogsAction:
+		return &DockerComposeLogsRequest{}, nil
+	case DockerServiceBuildAction:
+		return &DockerBuildRequest{}, nil
+	case DockerServiceTagAction:
+		return &DockerTagRequest{}, nil
+	case DockerServicePushAction:
+		return &DockerPushRequest{}, nil
+	case DockerServiceLoginAction:
+		return &DockerLoginRequest{}, nil
+	case DockerServiceNetworkCreateAction:
+		return &DockerNetworkCreateRequest{}, nil
+	case DockerServiceNetworkRemoveAction:
+		return &DockerNetworkRemoveRequest{}, nil
+	case DockerServiceVolumeCreateAction:
+		return &DockerVolumeCreateRequest{}, nil
+	case DockerServiceVolumeRemoveAction:
+		return &DockerVolumeRemoveRequest{}, nil
+	case DockerServiceCleanupAction:
+		return &DockerCleanupRequest{}, nil
+	case DockerServiceLogsAction:
+		return &DockerLogsRequest{}, nil
+	case DockerServiceCaptureLogsAction:
+		return &DockerCaptureLogsRequest{}, nil
 	}
 	return s.AbstractService.NewRequest(action)
 }
@@ -106,7 +213,12 @@ func (s *dockerService) Run(context *Context, request interface{}) *ServiceRespo
 		response.Response, err = s.runInContainer(context, actualRequest)
 		errorMessage = fmt.Sprintf("failed to run docker command %v in %v", actualRequest.Command, actualRequest.Target.Name)
 	case *DockerContainerStartRequest:
-		response.Response, err = s.startContainer(context, actualRequest)
+		var info *DockerContainerInfo
+		if err = s.validateWait(context, actualRequest.Target, actualRequest.API, actualRequest.Wait); err == nil {
+			if info, err = s.startContainer(context, actualRequest); err == nil {
+				response.Response, err = s.waitForContainer(context, actualRequest.Target, actualRequest.API, actualRequest.Wait, info)
+			}
+		}
 		errorMessage = fmt.Sprintf("failed start container %v", actualRequest.Target.Name)
 	case *DockerContainerStopRequest:
 		response.Response, err = s.stopContainer(context, actualRequest)
@@ -115,11 +227,63 @@ func (s *dockerService) Run(context *Context, request interface{}) *ServiceRespo
 		response.Response, err = s.removeContainer(context, actualRequest)
 		errorMessage = fmt.Sprintf("failed to remove container: %v", actualRequest.Target.Name)
 	case *DockerRunRequest:
-		response.Response, err = s.runContainer(context, actualRequest)
+		var info *DockerContainerInfo
+		if err = s.validateWait(context, actualRequest.Target, actualRequest.API, actualRequest.Wait); err == nil {
+			if info, err = s.runContainer(context, actualRequest); err == nil {
+				if err = s.captureContainerLogsIfNeeded(context, actualRequest.Target, actualRequest.API); err == nil {
+					response.Response, err = s.waitForContainer(context, actualRequest.Target, actualRequest.API, actualRequest.Wait, info)
+				}
+			}
+		}
 		errorMessage = fmt.Sprintf("failed to run container: %v", actualRequest.Target.Name)
 	case *DockerStopImagesRequest:
 		response.Response, err = s.stopImages(context, actualRequest)
 		errorMessage = fmt.Sprintf("failed to stop images: %v", actualRequest.Images)
+	case *DockerComposeUpRequest:
+		response.Response, err = s.composeUp(context, (*DockerComposeRequest)(actualRequest))
+		errorMessage = fmt.Sprintf("failed to run compose-up: %v", actualRequest.Project)
+	case *DockerComposeDownRequest:
+		response.Response, err = s.composeDown(context, (*DockerComposeRequest)(actualRequest))
+		errorMessage = fmt.Sprintf("failed to run compose-down: %v", actualRequest.Project)
+	case *DockerComposePsRequest:
+		response.Response, err = s.composePs(context, (*DockerComposeRequest)(actualRequest))
+		errorMessage = fmt.Sprintf("failed to run compose-ps: %v", actualRequest.Project)
+	case *DockerComposeLogsRequest:
+		response.Response, err = s.composeLogs(context, (*DockerComposeRequest)(actualRequest))
+		errorMessage = fmt.Sprintf("failed to run compose-logs: %v", actualRequest.Project)
+	case *DockerBuildRequest:
+		response.Response, err = s.build(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to build image: %v:%v", actualRequest.Repository, actualRequest.Tag)
+	case *DockerTagRequest:
+		response.Response, err = s.tag(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to tag image: %v", actualRequest.Source)
+	case *DockerPushRequest:
+		response.Response, err = s.push(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to push image: %v:%v", actualRequest.Repository, actualRequest.Tag)
+	case *DockerLoginRequest:
+		response.Response, err = s.login(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to login to registry: %v", actualRequest.Registry)
+	case *DockerNetworkCreateRequest:
+		response.Response, err = s.createNetwork(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to create network: %v", actualRequest.Name)
+	case *DockerNetworkRemoveRequest:
+		response.Response, err = s.removeNetwork(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to remove network: %v", actualRequest.Name)
+	case *DockerVolumeCreateRequest:
+		response.Response, err = s.createVolume(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to create volume: %v", actualRequest.Name)
+	case *DockerVolumeRemoveRequest:
+		response.Response, err = s.removeVolume(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to remove volume: %v", actualRequest.Name)
+	case *DockerCleanupRequest:
+		response.Response, err = s.cleanup(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to cleanup session: %v", actualRequest.SessionID)
+	case *DockerLogsRequest:
+		response.Response, err = s.logs(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to get logs: %v", actualRequest.Target.Name)
+	case *DockerCaptureLogsRequest:
+		response.Response, err = s.captureLogs(context, actualRequest)
+		errorMessage = fmt.Sprintf("failed to enable logs capture: %v", actualRequest.Directory)
 	default:
 		err = fmt.Errorf("unsupported request type: %T", request)
 	}
@@ -221,6 +385,9 @@ func (s *dockerService) runContainer(context *Context, request *DockerRunRequest
 	if err = request.Validate(); err != nil {
 		return nil, err
 	}
+	if request.API != "" {
+		return s.runContainerWithAPI(context, request)
+	}
 	s.applySysPathIfNeeded(request.SysPath)
 	var credentials = s.applyCredentialIfNeeded(request.Credentials)
 
@@ -247,6 +414,7 @@ func (s *dockerService) runContainer(context *Context, request *DockerRunRequest
 	if request.Workdir != "" {
 		args += fmt.Sprintf("-w %v ", context.Expand(request.Workdir))
 	}
+	args += s.runResourceArguments(context, request)
 	var params = ""
 	for k, v := range request.Params {
 		params += fmt.Sprintf("%v %v", k, v)
@@ -272,6 +440,552 @@ func (s *dockerService) runContainer(context *Context, request *DockerRunRequest
 	})
 }
 
+//runResourceArguments returns docker run network, label, restart policy, resource limit and entrypoint arguments
+func (s *dockerService) runResourceArguments(context *Context, request *DockerRunRequest) string {
+	var args = ""
+	if request.Network != "" {
+		args += fmt.Sprintf("--network %v ", context.Expand(request.Network))
+		if request.NetworkAlias != "" {
+			args += fmt.Sprintf("--network-alias %v ", context.Expand(request.NetworkAlias))
+		}
+	}
+	args += dockerLabelArguments(context, request.Labels)
+	if request.Restart != "" {
+		args += fmt.Sprintf("--restart %v ", request.Restart)
+	}
+	if request.Memory != "" {
+		args += fmt.Sprintf("--memory %v ", request.Memory)
+	}
+	if request.CPU != "" {
+		args += fmt.Sprintf("--cpus %v ", request.CPU)
+	}
+	if request.Entrypoint != "" {
+		args += fmt.Sprintf("--entrypoint '%v' ", context.Expand(request.Entrypoint))
+	}
+	return args
+}
+
+//dockerSessionLabels returns expanded labels with endly session id label
+func dockerSessionLabels(context *Context, labels map[string]string) map[string]string {
+	var result = make(map[string]string)
+	for k, v := range labels {
+		result[k] = context.Expand(v)
+	}
+	result[DockerSessionLabel] = context.SessionID
+	return result
+}
+
+func dockerLabelArguments(context *Context, labels map[string]string) string {
+	var args = ""
+	var sessionLabels = dockerSessionLabels(context, labels)
+	var keys = toolbox.MapKeysToStringSlice(sessionLabels)
+	sort.Strings(keys)
+	for _, key := range keys {
+		args += fmt.Sprintf("--label '%v=%v' ", key, sessionLabels[key])
+	}
+	return args
+}
+
+func (s *dockerService) createNetwork(context *Context, request *DockerNetworkCreateRequest) (*DockerNetworkInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if request.Driver == "" {
+		request.Driver = "bridge"
+	}
+	var name = context.Expand(request.Name)
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		return client.createNetwork(name, request.Driver, dockerSessionLabels(context, request.Labels))
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	if _, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerIgnoreErrors, "docker network create --driver %v %v%v 2>&1", request.Driver, dockerLabelArguments(context, request.Labels), name); err != nil && !strings.Contains(err.Error(), "already exists") {
+		return nil, err
+	}
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker network inspect --format '{{.Id}}|{{.Name}}|{{.Driver}}' %v", name)
+	if err != nil {
+		return nil, err
+	}
+	var columns = strings.Split(lastDockerOutputLine(commandResponse.Stdout()), "|")
+	if len(columns) < 3 {
+		return nil, fmt.Errorf("failed to inspect network %v, %v", name, commandResponse.Stdout())
+	}
+	return &DockerNetworkInfo{ID: columns[0], Name: columns[1], Driver: columns[2]}, nil
+}
+
+func (s *dockerService) removeNetwork(context *Context, request *DockerNetworkRemoveRequest) (*CommandResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	var name = context.Expand(request.Name)
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		var response = NewCommandResponse(request.API)
+		err = client.removeNetwork(name)
+		response.Add(NewCommandLog(fmt.Sprintf("DELETE /networks/%v", name), "", err))
+		return response, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	return s.removeDockerResource(context, request.Target, "network rm", name)
+}
+
+func (s *dockerService) createVolume(context *Context, request *DockerVolumeCreateRequest) (*DockerVolumeInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if request.Driver == "" {
+		request.Driver = "local"
+	}
+	var name = context.Expand(request.Name)
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		return client.createVolume(name, request.Driver, dockerSessionLabels(context, request.Labels))
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	if _, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker volume create --driver %v %v%v", request.Driver, dockerLabelArguments(context, request.Labels), name); err != nil {
+		return nil, err
+	}
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker volume inspect --format '{{.Name}}|{{.Driver}}|{{.Mountpoint}}' %v", name)
+	if err != nil {
+		return nil, err
+	}
+	var columns = strings.Split(lastDockerOutputLine(commandResponse.Stdout()), "|")
+	if len(columns) < 3 {
+		return nil, fmt.Errorf("failed to inspect volume %v, %v", name, commandResponse.Stdout())
+	}
+	return &DockerVolumeInfo{Name: columns[0], Driver: columns[1], Mountpoint: columns[2]}, nil
+}
+
+func (s *dockerService) removeVolume(context *Context, request *DockerVolumeRemoveRequest) (*CommandResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	var name = context.Expand(request.Name)
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		var response = NewCommandResponse(request.API)
+		err = client.removeVolume(name)
+		response.Add(NewCommandLog(fmt.Sprintf("DELETE /volumes/%v", name), "", err))
+		return response, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	return s.removeDockerResource(context, request.Target, "volume rm", name)
+}
+
+//removeDockerResource runs docker remove command, missing resource is not considered an error
+func (s *dockerService) removeDockerResource(context *Context, target *url.Resource, command string, names ...string) (*CommandResponse, error) {
+	response, err := s.executeSecureDockerCommand(nil, context, target, dockerIgnoreErrors, "docker %v %v 2>&1", command, strings.Join(names, " "))
+	if err != nil {
+		var message = strings.ToLower(err.Error())
+		if strings.Contains(message, "no such") || strings.Contains(message, "not found") {
+			return response, nil
+		}
+	}
+	return response, err
+}
+
+//listSessionResources returns unique ids of docker resources labeled with supplied session id, command is one of 'ps -a', 'network ls', 'volume ls' or 'images'
+func (s *dockerService) listSessionResources(context *Context, target *url.Resource, command, sessionID string) ([]string, error) {
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, target, dockerErrors, "docker %v -q --filter label=%v=%v", command, DockerSessionLabel, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	var unique = make(map[string]bool)
+	for _, line := range strings.Split(vtclean.Clean(commandResponse.Stdout(), false), "\n") {
+		line = strings.TrimSpace(line)
+		if dockerResourceIDExpr.MatchString(line) && !unique[line] {
+			unique[line] = true
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
+
+func (s *dockerService) cleanup(context *Context, request *DockerCleanupRequest) (*DockerCleanupResponse, error) {
+	if request.Target == nil {
+		return nil, fmt.Errorf("target was empty")
+	}
+	var response = &DockerCleanupResponse{SessionID: request.SessionID}
+	if response.SessionID == "" {
+		response.SessionID = context.SessionID
+	}
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		return client.cleanup(response.SessionID)
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	var resources = []struct {
+		list   string
+		remove string
+		result *[]string
+	}{
+		{"ps -a", "rm -f", &response.Containers},
+		{"network ls", "network rm", &response.Networks},
+		{"volume ls", "volume rm", &response.Volumes},
+		{"images", "rmi -f", &response.Images},
+	}
+	for _, resource := range resources {
+		ids, err := s.listSessionResources(context, request.Target, resource.list, response.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		*resource.result = ids
+		if len(ids) == 0 {
+			continue
+		}
+		if _, err = s.removeDockerResource(context, request.Target, resource.remove, ids...); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+var dockerResourceIDExpr = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+var dockerComposeVersionExpr = regexp.MustCompile(`(?m)^version:\s*['"]?([0-9.]+)`)
+
+func lastDockerOutputLine(stdout string) string {
+	var lines = strings.Split(strings.TrimSpace(vtclean.Clean(stdout, false)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func (s *dockerService) containerLogs(context *Context, target *url.Resource, API string, tail int) (string, error) {
+	response, err := s.logs(context, &DockerLogsRequest{Target: target, API: API, Tail: tail})
+	if err != nil {
+		return "", err
+	}
+	return response.Logs, nil
+}
+
+func (s *dockerService) logs(context *Context, request *DockerLogsRequest) (*DockerLogsResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if request.Follow && request.FollowTimeoutMs == 0 {
+		request.FollowTimeoutMs = 60000
+	}
+	var response = &DockerLogsResponse{Container: request.Target.Name}
+	if request.API != "" {
+		client, err := s.apiClient(context, request.API)
+		if err != nil {
+			return nil, err
+		}
+		if !request.Follow {
+			response.Logs, err = client.logs(request.Target.Name, dockerLogsQuery(request))
+			return response, err
+		}
+		reader, err := client.streamLogs(request.Target.Name, dockerLogsQuery(request))
+		if err != nil {
+			return nil, err
+		}
+		var timer = time.AfterFunc(time.Duration(request.FollowTimeoutMs)*time.Millisecond, func() {
+			_ = reader.Close()
+		})
+		defer timer.Stop()
+		var buffer = new(bytes.Buffer)
+		_ = copyDockerLogs(buffer, reader)
+		_ = reader.Close()
+		response.Logs = buffer.String()
+		return response, nil
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	var options = ""
+	if request.Tail > 0 {
+		options += fmt.Sprintf("--tail %v ", request.Tail)
+	}
+	if request.Since != "" {
+		options += fmt.Sprintf("--since %v ", request.Since)
+	}
+	var command = "docker logs"
+	if request.Follow {
+		var timeout = request.FollowTimeoutMs / 1000
+		if timeout == 0 {
+			timeout = 1
+		}
+		command = fmt.Sprintf("timeout %v docker logs -f", timeout)
+	}
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "%v %v%v 2>&1", command, options, request.Target.Name)
+	if err != nil {
+		return nil, err
+	}
+	response.Logs = commandResponse.Stdout()
+	return response, nil
+}
+
+//captureLogs enables capturing stdout and stderr of all containers started with docker run in this context
+func (s *dockerService) captureLogs(context *Context, request *DockerCaptureLogsRequest) (*DockerCaptureLogsResponse, error) {
+	var directory = request.Directory
+	if directory == "" {
+		if context.EventLogger != nil {
+			directory = path.Join(context.EventLogger.Directory(), "docker")
+		} else {
+			directory = path.Join(os.TempDir(), "endly", context.SessionID, "docker")
+		}
+	}
+	if err := os.MkdirAll(directory, 0744); err != nil {
+		return nil, err
+	}
+	if err := context.Put(dockerLogCaptureKey, &dockerLogCapture{Directory: directory, mutex: &sync.Mutex{}}); err != nil {
+		return nil, err
+	}
+	return &DockerCaptureLogsResponse{Directory: directory}, nil
+}
+
+//captureContainerLogsIfNeeded follows container logs if capture was enabled, when context is closed the follower is stopped and logs are copied into capture directory
+func (s *dockerService) captureContainerLogsIfNeeded(context *Context, target *url.Resource, API string) error {
+	if !context.Contains(dockerLogCaptureKey) {
+		return nil
+	}
+	var capture *dockerLogCapture
+	if !context.GetInto(dockerLogCaptureKey, &capture) || capture == nil {
+		return nil
+	}
+	var name = target.Name
+	var logFile = path.Join(capture.Directory, name+".log")
+	if API != "" {
+		client, err := s.apiClient(context, API)
+		if err != nil {
+			return err
+		}
+		reader, err := client.streamLogs(name, dockerLogsQuery(&DockerLogsRequest{Follow: true}))
+		if err != nil {
+			return err
+		}
+		writer, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			_ = reader.Close()
+			return err
+		}
+		go func() {
+			defer writer.Close()
+			_ = copyDockerLogs(writer, reader)
+		}()
+		context.Deffer(func() {
+			_ = reader.Close()
+		})
+		s.publishCapturedLogs(context, capture, name, toolbox.FileSchema+capture.Directory)
+		return nil
+	}
+	var hostDirectory = path.Join(DockerStagingDirectory, context.SessionID, "logs")
+	var hostLogFile = path.Join(hostDirectory, name+".log")
+	if _, err := context.Execute(target, fmt.Sprintf("mkdir -p %v", hostDirectory)); err != nil {
+		return err
+	}
+	response, err := s.executeSecureDockerCommand(nil, context, target, dockerErrors, "nohup docker logs -f %v > %v 2>&1 & echo $!", name, hostLogFile)
+	if err != nil {
+		return err
+	}
+	var stdout = strings.Split(strings.TrimSpace(response.Stdout()), "\n")
+	var followerPid = strings.TrimSpace(stdout[len(stdout)-1])
+	if toolbox.AsInt(followerPid) == 0 {
+		return fmt.Errorf("failed to capture %v logs, unable to get follower pid: %v", name, response.Stdout())
+	}
+	var hostURL = fmt.Sprintf("scp://%v%v", target.ParsedURL.Host, hostDirectory)
+	context.Deffer(func() {
+		if _, err := context.ExecuteAsSuperUser(target, &ExtractableCommand{
+			Executions: []*Execution{
+				{
+					Command: fmt.Sprintf("kill %v", followerPid),
+				},
+			},
+		}); err != nil {
+			log.Printf("failed to stop %v logs follower: %v", name, err)
+		}
+		var source = url.NewResource(toolbox.URLPathJoin(hostURL, name+".log"), target.Credential)
+		if _, err := context.Copy(false, source, url.NewResource(toolbox.FileSchema+logFile)); err != nil {
+			log.Printf("failed to copy %v logs: %v", name, err)
+		}
+	})
+	s.publishCapturedLogs(context, capture, name, hostURL)
+	return nil
+}
+
+//publishCapturedLogs puts container logs directory URL into the context state
+func (s *dockerService) publishCapturedLogs(context *Context, capture *dockerLogCapture, name, URL string) {
+	capture.mutex.Lock()
+	defer capture.mutex.Unlock()
+	var state = context.State()
+	var logs = data.NewMap()
+	if state.Has(DockerLogsStateKey) {
+		logs = state.GetMap(DockerLogsStateKey)
+	}
+	logs.Put(name, URL)
+	state.Put(DockerLogsStateKey, logs)
+}
+
+func (s *dockerService) containerHealth(context *Context, target *url.Resource, API string) (string, error) {
+	if API != "" {
+		client, err := s.apiClient(context, API)
+		if err != nil {
+			return "", err
+		}
+		info, err := client.inspectContainer(target.Name)
+		if err != nil || info == nil {
+			return "", err
+		}
+		return info.Health, nil
+	}
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, target, dockerErrors, "docker inspect --format '{{if .State.Health}}{{.State.Health.Status}}{{end}}' %v", target.Name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(vtclean.Clean(commandResponse.Stdout(), false)), nil
+}
+
+//validateWait checks if readiness probes can run against the docker host before container is started
+func (s *dockerService) validateWait(context *Context, target *url.Resource, API string, wait *DockerWait) error {
+	if wait == nil {
+		return nil
+	}
+	target, err := context.ExpandResource(target)
+	if err != nil {
+		return err
+	}
+	return wait.Validate(target, context.Expand(API))
+}
+
+//containerState returns true if container is running and its exit code otherwise
+func (s *dockerService) containerState(context *Context, target *url.Resource, API string) (bool, int, error) {
+	if API != "" {
+		client, err := s.apiClient(context, API)
+		if err != nil {
+			return false, 0, err
+		}
+		info, err := client.inspectContainer(target.Name)
+		if err != nil || info == nil {
+			return false, 0, err
+		}
+		return info.Status == "up", info.ExitCode, nil
+	}
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, target, dockerErrors, "docker inspect --format '{{.State.Status}} {{.State.ExitCode}}' %v", target.Name)
+	if err != nil {
+		return false, 0, err
+	}
+	var state = strings.Fields(vtclean.Clean(commandResponse.Stdout(), false))
+	if len(state) < 2 {
+		return false, 0, fmt.Errorf("failed to check %v state: %v", target.Name, commandResponse.Stdout())
+	}
+	return state[0] == "running", toolbox.AsInt(state[1]), nil
+}
+
+//probeContainer returns an empty string if container is ready, or a reason why it is not.
+func (s *dockerService) probeContainer(context *Context, target *url.Resource, API string, wait *DockerWait) (string, error) {
+	if wait.Health {
+		health, err := s.containerHealth(context, target, API)
+		if err != nil {
+			return "", err
+		}
+		if health == "" {
+			return "", fmt.Errorf("container %v does not define HEALTHCHECK", target.Name)
+		}
+		if health != "healthy" {
+			return fmt.Sprintf("health status: %v", health), nil
+		}
+	}
+	if wait.TCPAddress != "" {
+		var address = context.Expand(wait.TCPAddress)
+		connection, err := net.DialTimeout("tcp", address, time.Second)
+		if err != nil {
+			return fmt.Sprintf("tcp probe %v: %v", address, err), nil
+		}
+		_ = connection.Close()
+	}
+	if wait.HTTPURL != "" {
+		var URL = context.Expand(wait.HTTPURL)
+		client := &http.Client{Timeout: 2 * time.Second}
+		response, err := client.Get(URL)
+		if err != nil {
+			return fmt.Sprintf("http probe %v: %v", URL, err), nil
+		}
+		_ = response.Body.Close()
+		if response.StatusCode >= http.StatusBadRequest {
+			return fmt.Sprintf("http probe %v: status %v", URL, response.StatusCode), nil
+		}
+	}
+	if wait.LogPattern != "" {
+		expression, err := regexp.Compile(context.Expand(wait.LogPattern))
+		if err != nil {
+			return "", fmt.Errorf("invalid log pattern: %v, %v", wait.LogPattern, err)
+		}
+		logs, err := s.containerLogs(context, target, API, 0)
+		if err != nil {
+			return "", err
+		}
+		if !expression.MatchString(logs) {
+			return fmt.Sprintf("log pattern %v was not matched", wait.LogPattern), nil
+		}
+	}
+	if wait.Command != "" {
+		var command = context.Expand(wait.Command)
+		commandResponse, err := s.executeSecureDockerCommand(nil, context, target, nil, "docker exec %v %v; echo \"ENDLY_EXIT:$?\"", target.Name, command)
+		if err != nil {
+			return "", err
+		}
+		if !strings.Contains(commandResponse.Stdout(), "ENDLY_EXIT:0") {
+			return fmt.Sprintf("command '%v' failed", command), nil
+		}
+	}
+	return "", nil
+}
+
+//waitForContainer waits for container readiness if wait criteria was specified, it returns refreshed container info.
+func (s *dockerService) waitForContainer(context *Context, target *url.Resource, API string, wait *DockerWait, info *DockerContainerInfo) (*DockerContainerInfo, error) {
+	if wait == nil {
+		return info, nil
+	}
+	wait.Init()
+	var deadline = time.Now().Add(time.Duration(wait.TimeoutMs) * time.Millisecond)
+	var reason string
+	var err error
+	for {
+		if reason, err = s.probeContainer(context, target, API, wait); err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			break
+		}
+		//container that exited never becomes ready
+		running, exitCode, err := s.containerState(context, target, API)
+		if err != nil {
+			return nil, err
+		}
+		if !running {
+			logs, _ := s.containerLogs(context, target, API, wait.TailLines)
+			return nil, fmt.Errorf("container %v exited with code %v before it was ready, %v, last log lines:\n%v", target.Name, exitCode, reason, logs)
+		}
+		if time.Now().After(deadline) {
+			logs, _ := s.containerLogs(context, target, API, wait.TailLines)
+			return nil, fmt.Errorf("container %v was not ready after %v ms, %v, last log lines:\n%v", target.Name, wait.TimeoutMs, reason, logs)
+		}
+		time.Sleep(time.Duration(wait.SleepTimeMs) * time.Millisecond)
+	}
+	status, err := s.checkContainerProcesses(context, &DockerContainerStatusRequest{
+		Target: target,
+		Names:  target.Name,
+		API:    API,
+	})
+	if err == nil && len(status.Containers) == 1 {
+		return status.Containers[0], nil
+	}
+	return info, err
+}
+
 func (s *dockerService) checkContainerProcess(context *Context, request *DockerContainerStatusRequest) (*DockerContainerInfo, error) {
 	s.applySysPathIfNeeded(request.SysPath)
 	checkResponse, err := s.checkContainerProcesses(context, request)
@@ -290,6 +1004,9 @@ func (s *dockerService) startContainer(context *Context, request *DockerContaine
 	if request.Target.Name == "" {
 		return nil, fmt.Errorf("target name was empty url: %v", request.Target.URL)
 	}
+	if request.API != "" {
+		return s.startContainerWithAPI(context, request)
+	}
 	s.applySysPathIfNeeded(request.SysPath)
 	_, err := s.executeDockerCommand(nil, context, request.Target, dockerErrors, "docker start %v", request.Target.Name)
 	if err != nil {
@@ -307,6 +1024,9 @@ func (s *dockerService) stopContainer(context *Context, request *DockerContainer
 	if request.Target.Name == "" {
 		return nil, fmt.Errorf("target name was empty for %v", request.Target.URL)
 	}
+	if request.API != "" {
+		return s.stopContainerWithAPI(context, request)
+	}
 	s.applySysPathIfNeeded(request.SysPath)
 	info, err := s.checkContainerProcess(context, &DockerContainerStatusRequest{
 		Target:  request.Target,
@@ -334,6 +1054,9 @@ func (s *dockerService) removeContainer(context *Context, request *DockerContain
 	if request.Target.Name == "" {
 		return nil, fmt.Errorf("Target name was empty for %v", request.Target.URL)
 	}
+	if request.API != "" {
+		return s.removeContainerWithAPI(context, request)
+	}
 	s.applySysPathIfNeeded(request.SysPath)
 	commandInfo, err := s.executeDockerCommand(nil, context, request.Target, dockerErrors, "docker rm %v", request.Target.Name)
 	if err != nil {
@@ -379,6 +1102,9 @@ func (s *dockerService) runInContainer(context *Context, request *DockerContaine
 }
 
 func (s *dockerService) checkContainerProcesses(context *Context, request *DockerContainerStatusRequest) (*DockerContainerStatusResponse, error) {
+	if request.API != "" {
+		return s.checkContainerProcessesWithAPI(context, request)
+	}
 	s.applySysPathIfNeeded(request.SysPath)
 	info, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker ps")
 	if err != nil {
@@ -420,6 +1146,9 @@ func (s *dockerService) pullImage(context *Context, request *DockerPullRequest)
 	if request.Tag == "" {
 		request.Tag = "latest"
 	}
+	if request.API != "" {
+		return s.pullImageWithAPI(context, request)
+	}
 	info, err := s.executeDockerCommand(nil, context, request.Target, dockerErrors, "docker pull %v:%v", request.Repository, request.Tag)
 	if err != nil {
 		return nil, err
@@ -439,6 +1168,9 @@ func (s *dockerService) pullImage(context *Context, request *DockerPullRequest)
 }
 
 func (s *dockerService) checkImages(context *Context, request *DockerImagesRequest) (*DockerImagesResponse, error) {
+	if request.API != "" {
+		return s.checkImagesWithAPI(context, request)
+	}
 	if request.SysPath != nil {
 		s.SysPath = request.SysPath
 	}
@@ -499,6 +1231,507 @@ func (s *dockerService) checkImages(context *Context, request *DockerImagesReque
 
 }
 
+//isDockerHostResource returns true if resource is located on the docker host file system
+func isDockerHostResource(resource, dockerHost *url.Resource) bool {
+	switch resource.ParsedURL.Scheme {
+	case "scp", "ssh":
+		return resource.ParsedURL.Hostname() == dockerHost.ParsedURL.Hostname()
+	case "file":
+		var hostname = dockerHost.ParsedURL.Hostname()
+		return hostname == "127.0.0.1" || hostname == "localhost"
+	}
+	return false
+}
+
+//composeFile places expanded compose file on the docker host, it returns compose file path and project directory used to resolve relative paths.
+//Compose file on the docker host keeps its source directory as the project directory, otherwise source directory is copied to the project base directory first.
+func (s *dockerService) composeFile(context *Context, request *DockerComposeRequest) (string, string, error) {
+	source, err := context.ExpandResource(request.Source)
+	if err != nil {
+		return "", "", err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return "", "", err
+	}
+	sourceDirectory, name := toolbox.URLSplit(source.URL)
+	var baseDirectory = path.Join(DockerComposeBaseDirectory, request.ProjectName())
+	var baseURL = fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, baseDirectory)
+	var projectDirectory = baseDirectory
+	if isDockerHostResource(source, target) {
+		projectDirectory = path.Dir(source.ParsedURL.Path)
+	} else if _, err = context.Copy(false, url.NewResource(sourceDirectory, source.Credential), url.NewResource(baseURL, target.Credential)); err != nil {
+		return "", "", err
+	}
+	var composeResource = url.NewResource(toolbox.URLPathJoin(baseURL, name), target.Credential)
+	if _, err = context.Copy(true, source, composeResource); err != nil {
+		return "", "", err
+	}
+	return path.Join(baseDirectory, name), projectDirectory, nil
+}
+
+func (s *dockerService) composeCommand(context *Context, request *DockerComposeRequest, composeFiles []string, projectDirectory string, command string) string {
+	var env = ""
+	var keys = toolbox.MapKeysToStringSlice(request.Env)
+	sort.Strings(keys)
+	for _, key := range keys {
+		env += fmt.Sprintf("%v=%v ", key, quoteShellArgument(context.Expand(request.Env[key])))
+	}
+	if env != "" {
+		env = "env " + env
+	}
+	var files = ""
+	for _, composeFile := range composeFiles {
+		files += fmt.Sprintf("-f %v ", composeFile)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%vdocker-compose %v--project-directory %v -p %v %v %v", env, files, projectDirectory, request.ProjectName(), command, strings.Join(request.Services, " ")))
+}
+
+//composeLabelsFile places compose override file labeling project services and, since compose file format 2.1, default network with the endly session id.
+//Volumes are not labeled as labels conflict with external volumes, use compose-down with RemoveVolumes instead.
+func (s *dockerService) composeLabelsFile(context *Context, request *DockerComposeRequest, composeFile, projectDirectory string) (string, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return "", err
+	}
+	var hostURL = fmt.Sprintf("%v://%v", target.ParsedURL.Scheme, target.ParsedURL.Host)
+	content, err := downloadContent(context, url.NewResource(hostURL+composeFile, target.Credential))
+	if err != nil {
+		return "", err
+	}
+	var version = ""
+	if match := dockerComposeVersionExpr.FindStringSubmatch(string(content)); len(match) > 1 {
+		version = match[1]
+	} else if !strings.Contains(string(content), "services:") {
+		return "", nil //compose file format 1 does not support override with services
+	}
+	var configRequest = *request
+	configRequest.Services = nil
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "%v", s.composeCommand(context, &configRequest, []string{composeFile}, projectDirectory, "config --services"))
+	if err != nil {
+		return "", err
+	}
+	var labels = dockerSessionLabels(context, nil)
+	var services = make(map[string]interface{})
+	for _, line := range strings.Split(vtclean.Clean(commandResponse.Stdout(), false), "\n") {
+		line = strings.TrimSpace(line)
+		if dockerResourceIDExpr.MatchString(line) {
+			services[line] = map[string]interface{}{"labels": labels}
+		}
+	}
+	var override = map[string]interface{}{
+		"services": services,
+	}
+	if version != "" {
+		override["version"] = version
+	}
+	switch version {
+	case "2", "2.0":
+	default:
+		override["networks"] = map[string]interface{}{
+			"default": map[string]interface{}{"labels": labels},
+		}
+	}
+	overrideContent, err := json.MarshalIndent(override, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	var labelsFile = path.Join(path.Dir(composeFile), DockerComposeLabelsFile)
+	service, err := getStorageService(context, target)
+	if err != nil {
+		return "", err
+	}
+	defer service.Close()
+	if err = service.Upload(hostURL+labelsFile, bytes.NewReader(overrideContent)); err != nil {
+		return "", fmt.Errorf("failed to upload %v, %v", labelsFile, err)
+	}
+	return labelsFile, nil
+}
+
+func (s *dockerService) runComposeCommand(context *Context, request *DockerComposeRequest, command string, labeled bool) (*CommandResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	composeFile, projectDirectory, err := s.composeFile(context, request)
+	if err != nil {
+		return nil, err
+	}
+	var composeFiles = []string{composeFile}
+	if labeled {
+		labelsFile, err := s.composeLabelsFile(context, request, composeFile, projectDirectory)
+		if err != nil {
+			return nil, err
+		}
+		if labelsFile != "" {
+			composeFiles = append(composeFiles, labelsFile)
+		}
+	}
+	return s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "%v", s.composeCommand(context, request, composeFiles, projectDirectory, command))
+}
+
+func (s *dockerService) composeUp(context *Context, request *DockerComposeRequest) (*DockerComposeResponse, error) {
+	if _, err := s.runComposeCommand(context, request, "up -d", true); err != nil {
+		return nil, err
+	}
+	if !request.KeepRunning {
+		context.Deffer(func() {
+			_, _ = s.composeDown(context, request)
+		})
+	}
+	return s.composePs(context, request)
+}
+
+func (s *dockerService) composeDown(context *Context, request *DockerComposeRequest) (*DockerComposeResponse, error) {
+	var command = "down"
+	if request.RemoveVolumes {
+		command += " -v"
+	}
+	var downRequest = *request
+	downRequest.Services = nil //compose down does not support services subset
+	if _, err := s.runComposeCommand(context, &downRequest, command, false); err != nil {
+		return nil, err
+	}
+	return &DockerComposeResponse{
+		Project:  request.ProjectName(),
+		Services: make(map[string]*DockerContainerInfo),
+	}, nil
+}
+
+func (s *dockerService) composePs(context *Context, request *DockerComposeRequest) (*DockerComposeResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	var project = request.ProjectName()
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker ps -a --filter label=com.docker.compose.project=%v --format '%v'", project, dockerComposePsFormat)
+	if err != nil {
+		return nil, err
+	}
+	var response = &DockerComposeResponse{
+		Project:  project,
+		Services: extractComposeServices(commandResponse.Stdout()),
+	}
+	if len(request.Services) > 0 {
+		var services = make(map[string]*DockerContainerInfo)
+		for _, service := range request.Services {
+			if info, has := response.Services[service]; has {
+				services[service] = info
+			}
+		}
+		response.Services = services
+	}
+	return response, nil
+}
+
+func (s *dockerService) composeLogs(context *Context, request *DockerComposeRequest) (*DockerComposeLogsResponse, error) {
+	var command = "logs --no-color"
+	if request.Tail > 0 {
+		command += fmt.Sprintf(" --tail=%v", request.Tail)
+	}
+	commandResponse, err := s.runComposeCommand(context, request, command, false)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerComposeLogsResponse{
+		Project: request.ProjectName(),
+		Logs:    extractComposeLogs(commandResponse.Stdout()),
+	}, nil
+}
+
+const dockerComposePsFormat = `{{.ID}}|{{.Image}}|{{.Label "com.docker.compose.service"}}|{{.Status}}|{{.Ports}}|{{.Names}}|{{.Command}}`
+
+//extractComposeServices extracts containers keyed by compose service from docker ps output formatted with dockerComposePsFormat
+func extractComposeServices(stdout string) map[string]*DockerContainerInfo {
+	var result = make(map[string]*DockerContainerInfo)
+	for _, line := range strings.Split(stdout, "\n") {
+		var columns = strings.Split(strings.TrimSpace(vtclean.Clean(line, false)), "|")
+		if len(columns) < 7 {
+			continue
+		}
+		var status = "down"
+		if strings.HasPrefix(columns[3], "Up") {
+			status = "up"
+		}
+		info := &DockerContainerInfo{
+			ContainerID: columns[0],
+			Image:       columns[1],
+			Status:      status,
+			Port:        columns[4],
+			Names:       columns[5],
+			Command:     strings.Trim(columns[6], "\""),
+		}
+		if strings.Contains(columns[3], "(healthy)") {
+			info.Health = "healthy"
+		} else if strings.Contains(columns[3], "(unhealthy)") {
+			info.Health = "unhealthy"
+		} else if strings.Contains(columns[3], "(health: starting)") {
+			info.Health = "starting"
+		}
+		result[columns[2]] = info
+	}
+	return result
+}
+
+//extractComposeLogs extracts service logs from docker-compose logs output, where each line is prefixed with 'service_N  | '
+func extractComposeLogs(stdout string) map[string]string {
+	var result = make(map[string]string)
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimRight(line, "\r")
+		var index = strings.Index(line, "|")
+		if index == -1 {
+			continue
+		}
+		var service = strings.TrimSpace(line[:index])
+		if underscore := strings.LastIndex(service, "_"); underscore != -1 && toolbox.AsInt(service[underscore+1:]) > 0 {
+			service = service[:underscore]
+		}
+		result[service] += strings.TrimPrefix(line[index+1:], " ") + "\n"
+	}
+	return result
+}
+
+func (s *dockerService) dockerfile(context *Context, request *DockerBuildRequest) (string, error) {
+	source, err := context.ExpandResource(request.Dockerfile)
+	if err != nil {
+		return "", err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return "", err
+	}
+	var dockerfile = path.Join(dockerBuildDirectory(request.Repository), "Dockerfile")
+	var dockerfileResource = url.NewResource(fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, dockerfile), target.Credential)
+	if _, err = context.Copy(true, source, dockerfileResource); err != nil {
+		return "", err
+	}
+	return dockerfile, nil
+}
+
+//dockerBuildDirectory returns docker host directory for repository build files
+func dockerBuildDirectory(repository string) string {
+	return path.Join(DockerBuildBaseDirectory, strings.NewReplacer("/", "_", ":", "_").Replace(repository))
+}
+
+//dockerBuildEvents emits build step events, step event is emitted once the next step starts or the build ends
+type dockerBuildEvents struct {
+	image   string
+	emitted int
+	mutex   *sync.Mutex
+}
+
+func (e *dockerBuildEvents) emit(context *Context, stdout string, completed bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var events = extractDockerBuildEvents(e.image, stdout)
+	var count = len(events)
+	if !completed {
+		count-- //the last step may be still running
+	}
+	for ; e.emitted < count; e.emitted++ {
+		var event = events[e.emitted]
+		AddEvent(context, event, Pairs("value", event), Info)
+	}
+}
+
+//watchBuildLog emits build events from the build log while the build is running, until done is closed
+func (s *dockerService) watchBuildLog(context *Context, logResource *url.Resource, events *dockerBuildEvents, done chan bool) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(DockerBuildLogFrequency):
+		}
+		if content, err := downloadContent(context, logResource); err == nil {
+			events.emit(context, string(content), false)
+		}
+	}
+}
+
+func (s *dockerService) build(context *Context, request *DockerBuildRequest) (*DockerImageInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	if request.Tag == "" {
+		request.Tag = "latest"
+	}
+	var image = fmt.Sprintf("%v:%v", request.Repository, request.Tag)
+	var args = ""
+	if request.Dockerfile != nil {
+		dockerfile, err := s.dockerfile(context, request)
+		if err != nil {
+			return nil, err
+		}
+		args += fmt.Sprintf("-f %v ", dockerfile)
+	}
+	var keys = toolbox.MapKeysToStringSlice(request.BuildArgs)
+	sort.Strings(keys)
+	for _, key := range keys {
+		args += fmt.Sprintf("--build-arg %v='%v' ", key, context.Expand(request.BuildArgs[key]))
+	}
+	args += dockerLabelArguments(context, request.Labels)
+	if request.Stage != "" {
+		args += fmt.Sprintf("--target %v ", request.Stage)
+	}
+	if request.NoCache {
+		args += "--no-cache "
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	var logFile = path.Join(dockerBuildDirectory(request.Repository), "build.log")
+	if _, err = context.Execute(target, fmt.Sprintf("mkdir -p %v", path.Dir(logFile))); err != nil {
+		return nil, err
+	}
+	var events = &dockerBuildEvents{image: image, mutex: &sync.Mutex{}}
+	var done = make(chan bool)
+	go s.watchBuildLog(context.Clone(), url.NewResource(fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, logFile), target.Credential), events, done)
+	commandResponse, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerBuildErrors, "docker build %v-t %v %v 2>&1 | tee %v", args, image, context.Expand(request.Path), logFile)
+	close(done)
+	if commandResponse != nil {
+		events.emit(context, commandResponse.Stdout(), true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stdout = vtclean.Clean(commandResponse.Stdout(), false)
+	imageID := extractDockerImageID(stdout)
+	if imageID == "" {
+		return nil, fmt.Errorf("failed to build %v, %v", image, stdout)
+	}
+	return s.imageInfo(context, request.Target, request.Repository, request.Tag, imageID)
+}
+
+//imageInfo returns image info for supplied repository and tag, or basic info if image listing does not match
+func (s *dockerService) imageInfo(context *Context, target *url.Resource, repository, tag, imageID string) (*DockerImageInfo, error) {
+	imageResponse, err := s.checkImages(context, &DockerImagesRequest{Target: target, Repository: repository, Tag: tag})
+	if err != nil {
+		return nil, err
+	}
+	if len(imageResponse.Images) == 1 {
+		return imageResponse.Images[0], nil
+	}
+	return &DockerImageInfo{
+		Repository: repository,
+		Tag:        tag,
+		ImageID:    imageID,
+	}, nil
+}
+
+func (s *dockerService) tag(context *Context, request *DockerTagRequest) (*DockerImageInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	if request.Tag == "" {
+		request.Tag = "latest"
+	}
+	var source = context.Expand(request.Source)
+	var repository = context.Expand(request.Repository)
+	if _, err := s.executeSecureDockerCommand(nil, context, request.Target, dockerErrors, "docker tag %v %v:%v", source, repository, request.Tag); err != nil {
+		return nil, err
+	}
+	return s.imageInfo(context, request.Target, repository, request.Tag, "")
+}
+
+func (s *dockerService) push(context *Context, request *DockerPushRequest) (*DockerImageInfo, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	if request.Tag == "" {
+		request.Tag = "latest"
+	}
+	var repository = context.Expand(request.Repository)
+	if _, err := s.executeSecureDockerCommand(nil, context, request.Target, append(dockerRegistryErrors, dockerErrors...), "docker push %v:%v 2>&1", repository, request.Tag); err != nil {
+		return nil, err
+	}
+	return s.imageInfo(context, request.Target, repository, request.Tag, "")
+}
+
+func (s *dockerService) login(context *Context, request *DockerLoginRequest) (*DockerLoginResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	s.applySysPathIfNeeded(request.SysPath)
+	config, err := cred.NewConfig(request.Credential)
+	if err != nil {
+		return nil, err
+	}
+	var registry = context.Expand(request.Registry)
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	//password is piped to docker login run as super user, quoted credential key is replaced with shell quoted password
+	commandResponse, err := context.Execute(target, &ExtractableCommand{
+		Options: &ExecutionOptions{
+			SystemPaths: s.SysPath,
+			Terminators: []string{"Password"},
+		},
+		Executions: []*Execution{
+			{
+				Credentials: map[string]string{dockerRegistryCredentialKey: request.Credential},
+				Command:     fmt.Sprintf("printf '%%s' '%v' | sudo docker login -u %v --password-stdin %v 2>&1", dockerRegistryCredentialKey, quoteShellArgument(config.Username), quoteShellArgument(registry)),
+				Error:       append(dockerRegistryErrors, commandNotFound),
+			},
+			{
+				Credentials: map[string]string{sudoCredentialKey: target.Credential},
+				MatchOutput: "Password",
+				Command:     sudoCredentialKey,
+				Error:       append(dockerRegistryErrors, "Password"),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var stdout = vtclean.Clean(commandResponse.Stdout(), false)
+	if !strings.Contains(stdout, "Login Succeeded") {
+		return nil, fmt.Errorf("failed to login %v, %v", config.Username, stdout)
+	}
+	return &DockerLoginResponse{
+		Registry: registry,
+		Username: config.Username,
+	}, nil
+}
+
+var dockerBuildStepExpr = regexp.MustCompile(`^(Step \d+/\d+ : .+|#\d+ \[[^\]]+\] .+)$`)
+var dockerImageIDExpr = regexp.MustCompile(`(Successfully built |writing image sha256:)([0-9a-f]+)`)
+
+//extractDockerBuildEvents extracts build step events from docker build output, each event holds the step output
+func extractDockerBuildEvents(image, stdout string) []*DockerBuildEvent {
+	var result = make([]*DockerBuildEvent, 0)
+	var event *DockerBuildEvent
+	for _, line := range strings.Split(vtclean.Clean(stdout, false), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if dockerBuildStepExpr.MatchString(line) {
+			event = &DockerBuildEvent{Image: image, Step: line}
+			result = append(result, event)
+			continue
+		}
+		if event != nil && strings.TrimSpace(line) != "" {
+			event.Output += line + "\n"
+		}
+	}
+	return result
+}
+
+//extractDockerImageID extracts short image id from both classic and buildkit docker build output
+func extractDockerImageID(stdout string) string {
+	var matches = dockerImageIDExpr.FindAllStringSubmatch(stdout, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	var imageID = matches[len(matches)-1][2]
+	if len(imageID) > 12 {
+		imageID = imageID[:12]
+	}
+	return imageID
+}
+
 func (s *dockerService) executeDockerCommand(secure map[string]string, context *Context, target *url.Resource, errors []string, template string, arguments ...interface{}) (*CommandResponse, error) {
 	return s.executeSecureDockerCommand(secure, context, target, errors, template, arguments...)
 }
@@ -551,6 +1784,21 @@ func NewDockerService() Service {
 			DockerServiceContainerStopAction,
 			DockerServiceContainerStatusAction,
 			DockerServiceContainerRemoveAction,
+			DockerServiceComposeUpAction,
+			DockerServiceComposeDownAction,
+			DockerServiceComposePsAction,
+			DockerServiceComposeLogsAction,
+			DockerServiceBuildAction,
+			DockerServiceTagAction,
+			DockerServicePushAction,
+			DockerServiceLoginAction,
+			DockerServiceNetworkCreateAction,
+			DockerServiceNetworkRemoveAction,
+			DockerServiceVolumeCreateAction,
+			DockerServiceVolumeRemoveAction,
+			DockerServiceCleanupAction,
+			DockerServiceLogsAction,
+			DockerServiceCaptureLogsAction,
 		),
 	}
 	result.AbstractService.Service = result
@@ -0,0 +1,828 @@
+package endly
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/cred"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	url2 "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//DockerDefaultAPI represents default docker engine API unix socket endpoint
+const DockerDefaultAPI = "unix:///var/run/docker.sock"
+
+//dockerAPIClient represents docker engine http API client, it talks to unix socket or (tunneled) tcp socket
+type dockerAPIClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+type dockerAPIError struct {
+	Message string `json:"message"`
+}
+
+type dockerAPIPort struct {
+	IP          string
+	PrivatePort int
+	PublicPort  int
+	Type        string
+}
+
+type dockerAPIContainer struct {
+	ID      string `json:"Id"`
+	Names   []string
+	Image   string
+	Command string
+	State   string
+	Status  string
+	Ports   []*dockerAPIPort
+	Labels  map[string]string
+}
+
+type dockerAPIContainerState struct {
+	Status   string
+	Running  bool
+	ExitCode int
+	Health   *struct {
+		Status string
+	}
+}
+
+type dockerAPIContainerDetails struct {
+	ID     string `json:"Id"`
+	Name   string
+	Path   string
+	Args   []string
+	State  *dockerAPIContainerState
+	Config *struct {
+		Image  string
+		Labels map[string]string
+	}
+	NetworkSettings *struct {
+		Ports map[string][]*struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string
+		}
+	}
+}
+
+type dockerAPIImage struct {
+	ID       string `json:"Id"`
+	RepoTags []string
+	Size     int
+	Labels   map[string]string
+}
+
+type dockerAPIPortBinding struct {
+	HostIP   string `json:"HostIp,omitempty"`
+	HostPort string
+}
+
+type dockerAPIRestartPolicy struct {
+	Name string
+}
+
+type dockerAPIHostConfig struct {
+	Binds         []string                           `json:",omitempty"`
+	PortBindings  map[string][]*dockerAPIPortBinding `json:",omitempty"`
+	NetworkMode   string                             `json:",omitempty"`
+	RestartPolicy *dockerAPIRestartPolicy            `json:",omitempty"`
+	Memory        int64                              `json:",omitempty"`
+	NanoCpus      int64                              `json:",omitempty"`
+}
+
+type dockerAPIEndpointConfig struct {
+	Aliases []string `json:",omitempty"`
+}
+
+type dockerAPINetworkingConfig struct {
+	EndpointsConfig map[string]*dockerAPIEndpointConfig
+}
+
+type dockerAPINetwork struct {
+	ID     string `json:"Id"`
+	Name   string
+	Driver string
+	Labels map[string]string
+}
+
+type dockerAPIVolume struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+	Labels     map[string]string
+}
+
+type dockerAPICreateContainer struct {
+	Image            string
+	Env              []string               `json:",omitempty"`
+	Cmd              []string               `json:",omitempty"`
+	WorkingDir       string                 `json:",omitempty"`
+	ExposedPorts     map[string]interface{} `json:",omitempty"`
+	Labels           map[string]string      `json:",omitempty"`
+	Entrypoint       []string               `json:",omitempty"`
+	HostConfig       *dockerAPIHostConfig
+	NetworkingConfig *dockerAPINetworkingConfig `json:",omitempty"`
+}
+
+func (c *dockerAPIClient) do(method, URI string, query url2.Values, request, response interface{}) (int, error) {
+	code, content, err := c.doRaw(method, URI, query, request)
+	if err != nil {
+		return code, err
+	}
+	if response != nil && len(content) > 0 {
+		if err = json.Unmarshal(content, response); err != nil {
+			return code, fmt.Errorf("failed to decode %v %v response, %v", method, URI, err)
+		}
+	}
+	return code, nil
+}
+
+func (c *dockerAPIClient) doRaw(method, URI string, query url2.Values, request interface{}) (int, []byte, error) {
+	var body = new(bytes.Buffer)
+	if request != nil {
+		if err := json.NewEncoder(body).Encode(request); err != nil {
+			return 0, nil, err
+		}
+	}
+	var URL = c.baseURL + URI
+	if len(query) > 0 {
+		URL += "?" + query.Encode()
+	}
+	httpRequest, err := http.NewRequest(method, URL, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	httpRequest.Header.Set(ContentTypeKey, "application/json")
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer httpResponse.Body.Close()
+	content, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return httpResponse.StatusCode, nil, err
+	}
+	if httpResponse.StatusCode >= http.StatusBadRequest {
+		var apiError = &dockerAPIError{}
+		if json.Unmarshal(content, apiError) != nil || apiError.Message == "" {
+			apiError.Message = string(content)
+		}
+		return httpResponse.StatusCode, content, fmt.Errorf("%v %v: %v", method, URI, apiError.Message)
+	}
+	return httpResponse.StatusCode, content, nil
+}
+
+func (c *dockerAPIClient) containers(names, image string) ([]*DockerContainerInfo, error) {
+	var containers = make([]*dockerAPIContainer, 0)
+	if _, err := c.do("GET", "/containers/json", url2.Values{"all": []string{"1"}}, nil, &containers); err != nil {
+		return nil, err
+	}
+	var result = make([]*DockerContainerInfo, 0)
+	for _, container := range containers {
+		info := container.AsContainerInfo()
+		if image != "" && image != info.Image {
+			continue
+		}
+		if names != "" && names != info.Names {
+			continue
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+func (c *dockerAPIClient) inspectContainer(name string) (*DockerContainerInfo, error) {
+	var details = &dockerAPIContainerDetails{}
+	code, err := c.do("GET", fmt.Sprintf("/containers/%v/json", name), nil, nil, details)
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return details.AsContainerInfo(), nil
+}
+
+func (c *dockerAPIClient) images(repository, tag string) ([]*DockerImageInfo, error) {
+	var images = make([]*dockerAPIImage, 0)
+	if _, err := c.do("GET", "/images/json", nil, nil, &images); err != nil {
+		return nil, err
+	}
+	var result = make([]*DockerImageInfo, 0)
+	for _, image := range images {
+		for _, repoTag := range image.RepoTags {
+			info := image.AsImageInfo(repoTag)
+			if repository != "" && repository != info.Repository {
+				continue
+			}
+			if tag != "" && tag != info.Tag {
+				continue
+			}
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+func (c *dockerAPIClient) logs(name string, query url2.Values) (string, error) {
+	_, content, err := c.doRaw("GET", fmt.Sprintf("/containers/%v/logs", name), query, nil)
+	if err != nil {
+		return "", err
+	}
+	return demuxDockerLogs(content), nil
+}
+
+//streamLogs returns container logs stream, the caller is responsible for closing it
+func (c *dockerAPIClient) streamLogs(name string, query url2.Values) (io.ReadCloser, error) {
+	var URL = c.baseURL + fmt.Sprintf("/containers/%v/logs?", name) + query.Encode()
+	httpRequest, err := http.NewRequest("GET", URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var client = *c.client
+	client.Timeout = 0
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	if httpResponse.StatusCode >= http.StatusBadRequest {
+		content, _ := ioutil.ReadAll(httpResponse.Body)
+		_ = httpResponse.Body.Close()
+		return nil, fmt.Errorf("GET /containers/%v/logs: %s", name, content)
+	}
+	return httpResponse.Body, nil
+}
+
+//dockerLogsQuery returns docker logs API query for supplied request
+func dockerLogsQuery(request *DockerLogsRequest) url2.Values {
+	var query = url2.Values{"stdout": []string{"1"}, "stderr": []string{"1"}}
+	if request.Tail > 0 {
+		query.Set("tail", toolbox.AsString(request.Tail))
+	}
+	if request.Follow {
+		query.Set("follow", "1")
+	}
+	if request.Since != "" {
+		var since = request.Since
+		if duration, err := time.ParseDuration(since); err == nil {
+			since = toolbox.AsString(time.Now().Add(-duration).Unix())
+		} else if timestamp, err := time.Parse(time.RFC3339, since); err == nil {
+			since = toolbox.AsString(timestamp.Unix())
+		}
+		query.Set("since", since)
+	}
+	return query
+}
+
+func (c *dockerAPIClient) pullImage(repository, tag string) error {
+	_, err := c.do("POST", "/images/create", url2.Values{"fromImage": []string{repository}, "tag": []string{tag}}, nil, nil)
+	return err
+}
+
+func (c *dockerAPIClient) createContainer(name string, request *dockerAPICreateContainer) error {
+	_, err := c.do("POST", "/containers/create", url2.Values{"name": []string{name}}, request, nil)
+	return err
+}
+
+func (c *dockerAPIClient) startContainer(name string) error {
+	_, err := c.do("POST", fmt.Sprintf("/containers/%v/start", name), nil, nil, nil)
+	return err
+}
+
+func (c *dockerAPIClient) stopContainer(name string) error {
+	_, err := c.do("POST", fmt.Sprintf("/containers/%v/stop", name), nil, nil, nil)
+	return err
+}
+
+func (c *dockerAPIClient) removeContainer(name string) error {
+	code, err := c.do("DELETE", fmt.Sprintf("/containers/%v", name), url2.Values{"force": []string{"1"}}, nil, nil)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *dockerAPIClient) removeImage(name string) error {
+	code, err := c.do("DELETE", fmt.Sprintf("/images/%v", name), url2.Values{"force": []string{"1"}}, nil, nil)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *dockerAPIClient) createNetwork(name, driver string, labels map[string]string) (*DockerNetworkInfo, error) {
+	var request = &dockerAPINetwork{Name: name, Driver: driver, Labels: labels}
+	code, err := c.do("POST", "/networks/create", nil, request, nil)
+	if err != nil && code != http.StatusConflict {
+		return nil, err
+	}
+	var network = &dockerAPINetwork{}
+	if _, err = c.do("GET", fmt.Sprintf("/networks/%v", name), nil, nil, network); err != nil {
+		return nil, err
+	}
+	return &DockerNetworkInfo{ID: network.ID, Name: network.Name, Driver: network.Driver}, nil
+}
+
+func (c *dockerAPIClient) removeNetwork(name string) error {
+	code, err := c.do("DELETE", fmt.Sprintf("/networks/%v", name), nil, nil, nil)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (c *dockerAPIClient) createVolume(name, driver string, labels map[string]string) (*DockerVolumeInfo, error) {
+	var volume = &dockerAPIVolume{}
+	if _, err := c.do("POST", "/volumes/create", nil, &dockerAPIVolume{Name: name, Driver: driver, Labels: labels}, volume); err != nil {
+		return nil, err
+	}
+	return &DockerVolumeInfo{Name: volume.Name, Driver: volume.Driver, Mountpoint: volume.Mountpoint}, nil
+}
+
+func (c *dockerAPIClient) removeVolume(name string) error {
+	code, err := c.do("DELETE", fmt.Sprintf("/volumes/%v", name), nil, nil, nil)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+//cleanup removes containers, networks, volumes and images labeled with supplied session id
+func (c *dockerAPIClient) cleanup(sessionID string) (*DockerCleanupResponse, error) {
+	var filters, err = json.Marshal(map[string][]string{"label": {fmt.Sprintf("%v=%v", DockerSessionLabel, sessionID)}})
+	if err != nil {
+		return nil, err
+	}
+	var query = url2.Values{"all": []string{"1"}, "filters": []string{string(filters)}}
+	var response = &DockerCleanupResponse{SessionID: sessionID, Containers: make([]string, 0), Networks: make([]string, 0), Volumes: make([]string, 0), Images: make([]string, 0)}
+	var containers = make([]*dockerAPIContainer, 0)
+	if _, err = c.do("GET", "/containers/json", query, nil, &containers); err != nil {
+		return nil, err
+	}
+	for _, container := range containers {
+		if err = c.removeContainer(container.ID); err != nil {
+			return nil, err
+		}
+		response.Containers = append(response.Containers, container.ID)
+	}
+	var networks = make([]*dockerAPINetwork, 0)
+	if _, err = c.do("GET", "/networks", query, nil, &networks); err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		if err = c.removeNetwork(network.ID); err != nil {
+			return nil, err
+		}
+		response.Networks = append(response.Networks, network.Name)
+	}
+	var volumes = &struct {
+		Volumes []*dockerAPIVolume
+	}{}
+	if _, err = c.do("GET", "/volumes", query, nil, volumes); err != nil {
+		return nil, err
+	}
+	for _, volume := range volumes.Volumes {
+		if err = c.removeVolume(volume.Name); err != nil {
+			return nil, err
+		}
+		response.Volumes = append(response.Volumes, volume.Name)
+	}
+	var images = make([]*dockerAPIImage, 0)
+	if _, err = c.do("GET", "/images/json", query, nil, &images); err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		if err = c.removeImage(image.ID); err != nil {
+			return nil, err
+		}
+		response.Images = append(response.Images, image.ID)
+	}
+	return response, nil
+}
+
+//AsContainerInfo converts API container into DockerContainerInfo
+func (c *dockerAPIContainer) AsContainerInfo() *DockerContainerInfo {
+	var names = make([]string, 0)
+	for _, name := range c.Names {
+		names = append(names, strings.TrimPrefix(name, "/"))
+	}
+	var status = "down"
+	if c.State == "running" {
+		status = "up"
+	}
+	var result = &DockerContainerInfo{
+		ContainerID: c.ID,
+		Image:       c.Image,
+		Command:     c.Command,
+		Status:      status,
+		Names:       strings.Join(names, ","),
+		Labels:      c.Labels,
+		Ports:       make([]*DockerPortBinding, 0),
+	}
+	if strings.Contains(c.Status, "(healthy)") {
+		result.Health = "healthy"
+	} else if strings.Contains(c.Status, "(unhealthy)") {
+		result.Health = "unhealthy"
+	} else if strings.Contains(c.Status, "(health: starting)") {
+		result.Health = "starting"
+	}
+	for _, port := range c.Ports {
+		result.Ports = append(result.Ports, &DockerPortBinding{
+			ContainerPort: port.PrivatePort,
+			HostPort:      port.PublicPort,
+			HostIP:        port.IP,
+			Protocol:      port.Type,
+		})
+	}
+	result.Port = formatDockerPorts(result.Ports)
+	return result
+}
+
+//AsContainerInfo converts API container details into DockerContainerInfo
+func (c *dockerAPIContainerDetails) AsContainerInfo() *DockerContainerInfo {
+	var result = &DockerContainerInfo{
+		ContainerID: c.ID,
+		Names:       strings.TrimPrefix(c.Name, "/"),
+		Command:     strings.TrimSpace(c.Path + " " + strings.Join(c.Args, " ")),
+		Status:      "down",
+		Ports:       make([]*DockerPortBinding, 0),
+	}
+	if c.Config != nil {
+		result.Image = c.Config.Image
+		result.Labels = c.Config.Labels
+	}
+	if c.State != nil {
+		if c.State.Running {
+			result.Status = "up"
+		}
+		result.ExitCode = c.State.ExitCode
+		if c.State.Health != nil {
+			result.Health = c.State.Health.Status
+		}
+	}
+	if c.NetworkSettings != nil {
+		for containerPort, bindings := range c.NetworkSettings.Ports {
+			var port, protocol = containerPort, "tcp"
+			if pair := strings.Split(containerPort, "/"); len(pair) == 2 {
+				port, protocol = pair[0], pair[1]
+			}
+			for _, binding := range bindings {
+				result.Ports = append(result.Ports, &DockerPortBinding{
+					ContainerPort: toolbox.AsInt(port),
+					HostPort:      toolbox.AsInt(binding.HostPort),
+					HostIP:        binding.HostIP,
+					Protocol:      protocol,
+				})
+			}
+		}
+		sort.Slice(result.Ports, func(i, j int) bool {
+			return result.Ports[i].ContainerPort < result.Ports[j].ContainerPort
+		})
+	}
+	result.Port = formatDockerPorts(result.Ports)
+	return result
+}
+
+//AsImageInfo converts API image into DockerImageInfo for supplied repository tag
+func (i *dockerAPIImage) AsImageInfo(repoTag string) *DockerImageInfo {
+	repository, tag := splitDockerImage(repoTag)
+	var imageID = strings.TrimPrefix(i.ID, "sha256:")
+	if len(imageID) > 12 {
+		imageID = imageID[:12]
+	}
+	return &DockerImageInfo{
+		Repository: repository,
+		Tag:        tag,
+		ImageID:    imageID,
+		Size:       i.Size,
+		Labels:     i.Labels,
+	}
+}
+
+func formatDockerPorts(ports []*DockerPortBinding) string {
+	var result = make([]string, 0)
+	for _, port := range ports {
+		if port.HostPort > 0 {
+			result = append(result, fmt.Sprintf("%v:%v->%v/%v", port.HostIP, port.HostPort, port.ContainerPort, port.Protocol))
+			continue
+		}
+		result = append(result, fmt.Sprintf("%v/%v", port.ContainerPort, port.Protocol))
+	}
+	return strings.Join(result, ", ")
+}
+
+//demuxDockerLogs converts multiplexed stdout/stderr log stream (8 bytes frame header) into plain text, non multiplexed (tty) content is returned as is.
+func demuxDockerLogs(content []byte) string {
+	var result = new(bytes.Buffer)
+	for offset := 0; offset < len(content); {
+		if len(content)-offset < 8 || content[offset] > 2 || content[offset+1] != 0 || content[offset+2] != 0 || content[offset+3] != 0 {
+			return string(content)
+		}
+		var size = int(binary.BigEndian.Uint32(content[offset+4 : offset+8]))
+		offset += 8
+		if offset+size > len(content) {
+			return string(content)
+		}
+		result.Write(content[offset : offset+size])
+		offset += size
+	}
+	return result.String()
+}
+
+//copyDockerLogs copies multiplexed docker log stream into writer, non multiplexed (tty) stream is copied as is
+func copyDockerLogs(writer io.Writer, reader io.Reader) error {
+	var header = make([]byte, 8)
+	for {
+		read, err := io.ReadFull(reader, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			_, _ = writer.Write(header[:read])
+			if err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+		if header[0] > 2 || header[1] != 0 || header[2] != 0 || header[3] != 0 {
+			if _, err = writer.Write(header); err == nil {
+				_, err = io.Copy(writer, reader)
+			}
+			return err
+		}
+		if _, err = io.CopyN(writer, reader, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+//expandDockerSecrets replaces credential keys in the supplied text with the corresponding credential password
+func expandDockerSecrets(credentials map[string]string, text string) (string, error) {
+	for key, credentialPath := range credentials {
+		if !strings.Contains(text, key) {
+			continue
+		}
+		config, err := cred.NewConfig(credentialPath)
+		if err != nil {
+			return "", fmt.Errorf("invalid credential %v, %v", key, err)
+		}
+		text = strings.Replace(text, key, config.Password, -1)
+	}
+	return text, nil
+}
+
+func (s *dockerService) apiClient(context *Context, endpoint string) (*dockerAPIClient, error) {
+	return newDockerAPIClient(context.Expand(endpoint))
+}
+
+func (s *dockerService) checkContainerProcessesWithAPI(context *Context, request *DockerContainerStatusRequest) (*DockerContainerStatusResponse, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := client.containers(request.Names, request.Image)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerContainerStatusResponse{Containers: containers}, nil
+}
+
+func (s *dockerService) checkImagesWithAPI(context *Context, request *DockerImagesRequest) (*DockerImagesResponse, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	images, err := client.images(request.Repository, request.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerImagesResponse{Images: images}, nil
+}
+
+func (s *dockerService) pullImageWithAPI(context *Context, request *DockerPullRequest) (*DockerImageInfo, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.pullImage(request.Repository, request.Tag); err != nil {
+		return nil, err
+	}
+	images, err := client.images(request.Repository, request.Tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 1 {
+		return images[0], nil
+	}
+	return nil, fmt.Errorf("failed to check image status: %v:%v found: %v", request.Repository, request.Tag, len(images))
+}
+
+func (s *dockerService) buildAPICreateRequest(context *Context, request *DockerRunRequest) (*dockerAPICreateContainer, error) {
+	var result = &dockerAPICreateContainer{
+		Image:        request.Image,
+		Env:          make([]string, 0),
+		Cmd:          make([]string, 0),
+		WorkingDir:   context.Expand(request.Workdir),
+		ExposedPorts: make(map[string]interface{}),
+		HostConfig: &dockerAPIHostConfig{
+			Binds:        make([]string, 0),
+			PortBindings: make(map[string][]*dockerAPIPortBinding),
+		},
+	}
+	for k, v := range request.Env {
+		value, err := expandDockerSecrets(request.Credentials, context.Expand(v))
+		if err != nil {
+			return nil, err
+		}
+		result.Env = append(result.Env, fmt.Sprintf("%v=%v", k, value))
+	}
+	for k, v := range request.Mount {
+		result.HostConfig.Binds = append(result.HostConfig.Binds, fmt.Sprintf("%v:%v", context.Expand(k), context.Expand(v)))
+	}
+	for k, v := range request.MappedPort {
+		var containerPort = context.Expand(toolbox.AsString(v))
+		if !strings.Contains(containerPort, "/") {
+			containerPort += "/tcp"
+		}
+		result.ExposedPorts[containerPort] = struct{}{}
+		result.HostConfig.PortBindings[containerPort] = append(result.HostConfig.PortBindings[containerPort], &dockerAPIPortBinding{
+			HostPort: context.Expand(toolbox.AsString(k)),
+		})
+	}
+	for k, v := range request.Params {
+		result.Cmd = append(result.Cmd, k, v)
+	}
+	result.Labels = dockerSessionLabels(context, request.Labels)
+	if request.Entrypoint != "" {
+		result.Entrypoint = strings.Split(context.Expand(request.Entrypoint), " ")
+	}
+	if request.Network != "" {
+		var network = context.Expand(request.Network)
+		result.HostConfig.NetworkMode = network
+		var endpoint = &dockerAPIEndpointConfig{}
+		if request.NetworkAlias != "" {
+			endpoint.Aliases = []string{context.Expand(request.NetworkAlias)}
+		}
+		result.NetworkingConfig = &dockerAPINetworkingConfig{
+			EndpointsConfig: map[string]*dockerAPIEndpointConfig{network: endpoint},
+		}
+	}
+	if request.Restart != "" {
+		result.HostConfig.RestartPolicy = &dockerAPIRestartPolicy{Name: request.Restart}
+	}
+	var err error
+	if request.Memory != "" {
+		if result.HostConfig.Memory, err = dockerMemoryBytes(request.Memory); err != nil {
+			return nil, err
+		}
+	}
+	if request.CPU != "" {
+		cpu, err := strconv.ParseFloat(request.CPU, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU limit: %v, %v", request.CPU, err)
+		}
+		result.HostConfig.NanoCpus = int64(cpu * 1e9)
+	}
+	return result, nil
+}
+
+//dockerMemoryBytes converts memory limit with b, k, m or g unit suffix into bytes, unit can be followed by b, i.e. 512mb
+func dockerMemoryBytes(memory string) (int64, error) {
+	var value = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(memory)), "b")
+	var factor int64 = 1
+	if len(value) > 0 {
+		switch value[len(value)-1] {
+		case 'k':
+			factor, value = 1024, value[:len(value)-1]
+		case 'm':
+			factor, value = 1024*1024, value[:len(value)-1]
+		case 'g':
+			factor, value = 1024*1024*1024, value[:len(value)-1]
+		}
+	}
+	result, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit: %v, %v", memory, err)
+	}
+	return result * factor, nil
+}
+
+func (s *dockerService) runContainerWithAPI(context *Context, request *DockerRunRequest) (*DockerContainerInfo, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	var name = request.Target.Name
+	if err = client.removeContainer(name); err != nil {
+		return nil, err
+	}
+	createRequest, err := s.buildAPICreateRequest(context, request)
+	if err != nil {
+		return nil, err
+	}
+	err = client.createContainer(name, createRequest)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "no such image") {
+		repository, tag := splitDockerImage(request.Image)
+		if err = client.pullImage(repository, tag); err == nil {
+			err = client.createContainer(name, createRequest)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = client.startContainer(name); err != nil {
+		return nil, err
+	}
+	return client.inspectContainer(name)
+}
+
+func (s *dockerService) startContainerWithAPI(context *Context, request *DockerContainerStartRequest) (*DockerContainerInfo, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.startContainer(request.Target.Name); err != nil {
+		return nil, err
+	}
+	return client.inspectContainer(request.Target.Name)
+}
+
+func (s *dockerService) stopContainerWithAPI(context *Context, request *DockerContainerStopRequest) (*DockerContainerInfo, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.inspectContainer(request.Target.Name)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	if err = client.stopContainer(request.Target.Name); err != nil {
+		return nil, err
+	}
+	return client.inspectContainer(request.Target.Name)
+}
+
+func (s *dockerService) removeContainerWithAPI(context *Context, request *DockerContainerRemoveRequest) (*CommandResponse, error) {
+	client, err := s.apiClient(context, request.API)
+	if err != nil {
+		return nil, err
+	}
+	var response = NewCommandResponse(request.API)
+	err = client.removeContainer(request.Target.Name)
+	response.Add(NewCommandLog(fmt.Sprintf("DELETE /containers/%v", request.Target.Name), "", err))
+	return response, err
+}
+
+//splitDockerImage splits image into repository and tag, tag defaults to latest
+func splitDockerImage(image string) (string, string) {
+	if index := strings.LastIndex(image, ":"); index != -1 && !strings.Contains(image[index:], "/") {
+		return image[:index], image[index+1:]
+	}
+	return image, "latest"
+}
+
+func newDockerAPIClient(endpoint string) (*dockerAPIClient, error) {
+	parsedURL, err := url2.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker API endpoint: %v, %v", endpoint, err)
+	}
+	var transport = &http.Transport{}
+	var baseURL string
+	switch parsedURL.Scheme {
+	case "unix":
+		var socket = parsedURL.Path
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			var dialer = &net.Dialer{}
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://docker"
+	case "tcp", "http":
+		baseURL = "http://" + parsedURL.Host
+	case "https":
+		baseURL = "https://" + parsedURL.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker API endpoint scheme: %v", endpoint)
+	}
+	return &dockerAPIClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   5 * time.Minute,
+		},
+		baseURL: baseURL,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package endly
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDockerMemoryBytes(t *testing.T) {
+	var useCases = []struct {
+		memory   string
+		expected int64
+	}{
+		{"1024", 1024},
+		{"512b", 512},
+		{"1k", 1024},
+		{"1kb", 1024},
+		{"512m", 512 * 1024 * 1024},
+		{"512mb", 512 * 1024 * 1024},
+		{"1G", 1024 * 1024 * 1024},
+		{"1gb", 1024 * 1024 * 1024},
+	}
+	for _, useCase := range useCases {
+		actual, err := dockerMemoryBytes(useCase.memory)
+		if assert.Nil(t, err, useCase.memory) {
+			assert.EqualValues(t, useCase.expected, actual, useCase.memory)
+		}
+	}
+	_, err := dockerMemoryBytes("1tb")
+	assert.NotNil(t, err)
+}
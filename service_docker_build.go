@@ -0,0 +1,108 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+)
+
+//DockerBuildRequest represents a docker build request
+type DockerBuildRequest struct {
+	Target     *url.Resource     //docker host
+	SysPath    []string          //system path
+	Path       string            //build context directory on the docker host
+	Dockerfile *url.Resource     //optional Dockerfile URL, its content is expanded with the context state and copied to the docker host
+	Repository string            //image repository
+	Tag        string            //image tag, latest by default
+	BuildArgs  map[string]string //build time variables
+	Labels     map[string]string //image labels, endly session id label is always added
+	Stage      string            //optional target build stage
+	NoCache    bool              //do not use cache when building the image
+}
+
+//DockerBuildEvent represents a docker build step event
+type DockerBuildEvent struct {
+	Image  string
+	Step   string
+	Output string
+}
+
+//DockerTagRequest represents a docker tag request
+type DockerTagRequest struct {
+	Target     *url.Resource
+	SysPath    []string
+	Source     string //source image, i.e. myapp:1.0
+	Repository string //target repository, i.e. registry.example.com/myapp
+	Tag        string //target tag, latest by default
+}
+
+//DockerPushRequest represents a docker push request
+type DockerPushRequest struct {
+	Target     *url.Resource
+	SysPath    []string
+	Repository string
+	Tag        string
+}
+
+//DockerLoginRequest represents a docker registry login request
+type DockerLoginRequest struct {
+	Target     *url.Resource
+	SysPath    []string
+	Registry   string //registry host, docker hub if empty
+	Credential string //registry credential file, password is never written to the command log
+}
+
+//DockerLoginResponse represents a docker registry login response
+type DockerLoginResponse struct {
+	Registry string
+	Username string
+}
+
+//Validate checks if request is valid
+func (r *DockerBuildRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Path == "" {
+		return fmt.Errorf("build context path was empty for %v", r.Target.URL)
+	}
+	if r.Repository == "" {
+		return fmt.Errorf("repository was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerTagRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Source == "" {
+		return fmt.Errorf("source image was empty for %v", r.Target.URL)
+	}
+	if r.Repository == "" {
+		return fmt.Errorf("repository was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerPushRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Repository == "" {
+		return fmt.Errorf("repository was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerLoginRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Credential == "" {
+		return fmt.Errorf("credential was empty for %v", r.Target.URL)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+)
+
+//DockerComposeRequest represents a docker compose request
+type DockerComposeRequest struct {
+	Target        *url.Resource     //docker host, target name is used as the default project name
+	SysPath       []string          //system path
+	Source        *url.Resource     //compose file URL, its content is expanded with the context state
+	Project       string            //compose project name
+	Services      []string          //optional subset of services
+	Env           map[string]string //environment overrides
+	Tail          int               //compose-logs only: number of lines to show from the end of the logs
+	RemoveVolumes bool              //compose-down only: remove named volumes declared in compose file
+	KeepRunning   bool              //compose-up only: disables automatic compose-down when context is closed
+}
+
+//DockerComposeUpRequest represents a docker compose-up request
+type DockerComposeUpRequest DockerComposeRequest
+
+//DockerComposeDownRequest represents a docker compose-down request
+type DockerComposeDownRequest DockerComposeRequest
+
+//DockerComposePsRequest represents a docker compose-ps request
+type DockerComposePsRequest DockerComposeRequest
+
+//DockerComposeLogsRequest represents a docker compose-logs request
+type DockerComposeLogsRequest DockerComposeRequest
+
+//DockerComposeResponse represents a docker compose response with containers keyed by service name
+type DockerComposeResponse struct {
+	Project  string
+	Services map[string]*DockerContainerInfo
+}
+
+//DockerComposeLogsResponse represents a docker compose logs keyed by service name
+type DockerComposeLogsResponse struct {
+	Project string
+	Logs    map[string]string
+}
+
+//Validate checks if request is valid
+func (r *DockerComposeRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Source == nil {
+		return fmt.Errorf("compose source was empty for %v", r.Target.URL)
+	}
+	if r.ProjectName() == "" {
+		return fmt.Errorf("project was empty for %v", r.Source.URL)
+	}
+	return nil
+}
+
+//ProjectName returns compose project name
+func (r *DockerComposeRequest) ProjectName() string {
+	if r.Project != "" {
+		return r.Project
+	}
+	if r.Target != nil {
+		return r.Target.Name
+	}
+	return ""
+}
@@ -10,6 +10,7 @@ type DockerContainerStatusRequest struct {
 	SysPath []string
 	Names   string
 	Image   string
+	API     string //optional docker engine API endpoint, docker CLI is used if empty
 }
 
 //DockerContainerStatusResponse represents a docker container check response
@@ -22,18 +23,22 @@ type DockerContainerStatusResponse struct {
 type DockerContainerStartRequest struct {
 	SysPath []string
 	Target  *url.Resource
+	API     string      //optional docker engine API endpoint, docker CLI is used if empty
+	Wait    *DockerWait //optional readiness criteria
 }
 
 //DockerContainerRemoveRequest represents a docker remove container request
 type DockerContainerRemoveRequest struct {
 	SysPath []string
 	Target  *url.Resource
+	API     string //optional docker engine API endpoint, docker CLI is used if empty
 }
 
 //DockerContainerStopRequest represents a docker stop container request.
 type DockerContainerStopRequest struct {
 	SysPath []string
 	Target  *url.Resource
+	API     string //optional docker engine API endpoint, docker CLI is used if empty
 }
 
 //DockerContainerCommandRequest represents a docker run command in the container.
@@ -55,4 +60,16 @@ type DockerContainerInfo struct {
 	Status      string
 	Port        string
 	Names       string
+	ExitCode    int                  //container exit code (engine API only)
+	Health      string               //container health status: starting, healthy or unhealthy
+	Labels      map[string]string    //container labels (engine API only)
+	Ports       []*DockerPortBinding //container port bindings (engine API only)
+}
+
+//DockerPortBinding represents a container port binding
+type DockerPortBinding struct {
+	ContainerPort int
+	HostPort      int
+	HostIP        string
+	Protocol      string
 }
@@ -10,6 +10,7 @@ type DockerImagesRequest struct {
 	Target     *url.Resource
 	Repository string
 	Tag        string
+	API        string //optional docker engine API endpoint, docker CLI is used if empty
 }
 
 //DockerImagesResponse represents a docker check image response
@@ -23,4 +24,5 @@ type DockerImageInfo struct {
 	Tag        string
 	ImageID    string
 	Size       int
+	Labels     map[string]string //image labels (engine API only)
 }
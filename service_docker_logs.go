@@ -0,0 +1,56 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"sync"
+)
+
+//DockerLogsStateKey represents a state key holding captured container log directory URLs keyed by container name, i.e. ${dockerLogs.db1} can be used as validator/log listen source
+const DockerLogsStateKey = "dockerLogs"
+
+//DockerLogsRequest represents a docker logs request
+type DockerLogsRequest struct {
+	Target          *url.Resource //docker host, target name is used as container name
+	SysPath         []string      //system path
+	Since           string        //show logs since timestamp i.e. 2018-01-02T13:23:37Z or relative duration i.e. 42m
+	Tail            int           //number of lines to show from the end of the logs
+	Follow          bool          //follow log output until container stops or follow timeout elapses
+	FollowTimeoutMs int           //follow timeout, 60000 by default
+	API             string        //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerLogsResponse represents a docker logs response
+type DockerLogsResponse struct {
+	Container string
+	Logs      string
+}
+
+//DockerCaptureLogsRequest represents a request to capture stdout and stderr of every container started with docker run until the context is closed
+type DockerCaptureLogsRequest struct {
+	Directory string //local directory for captured logs, docker subdirectory of the event logger directory is used if empty
+}
+
+//DockerCaptureLogsResponse represents a docker capture-logs response
+type DockerCaptureLogsResponse struct {
+	Directory string
+}
+
+//Validate checks if request is valid
+func (r *DockerLogsRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Target.Name == "" {
+		return fmt.Errorf("target name was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//dockerLogCapture represents session container logs capture settings
+type dockerLogCapture struct {
+	Directory string
+	mutex     *sync.Mutex
+}
+
+var dockerLogCaptureKey = (*dockerLogCapture)(nil)
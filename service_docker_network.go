@@ -0,0 +1,117 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+)
+
+//DockerNetworkCreateRequest represents a docker network-create request, created network is labeled with endly session id
+type DockerNetworkCreateRequest struct {
+	Target  *url.Resource     //docker host
+	SysPath []string          //system path
+	Name    string            //network name
+	Driver  string            //network driver, bridge by default
+	Labels  map[string]string //network labels
+	API     string            //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerNetworkRemoveRequest represents a docker network-remove request
+type DockerNetworkRemoveRequest struct {
+	Target  *url.Resource
+	SysPath []string
+	Name    string
+	API     string //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerNetworkInfo represents a docker network info
+type DockerNetworkInfo struct {
+	ID     string
+	Name   string
+	Driver string
+}
+
+//DockerVolumeCreateRequest represents a docker volume-create request, created volume is labeled with endly session id
+type DockerVolumeCreateRequest struct {
+	Target  *url.Resource     //docker host
+	SysPath []string          //system path
+	Name    string            //volume name
+	Driver  string            //volume driver, local by default
+	Labels  map[string]string //volume labels
+	API     string            //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerVolumeRemoveRequest represents a docker volume-remove request
+type DockerVolumeRemoveRequest struct {
+	Target  *url.Resource
+	SysPath []string
+	Name    string
+	API     string //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerVolumeInfo represents a docker volume info
+type DockerVolumeInfo struct {
+	Name       string
+	Driver     string
+	Mountpoint string
+}
+
+//DockerCleanupRequest represents a request to remove containers, networks, volumes and images labeled with a session id
+type DockerCleanupRequest struct {
+	Target    *url.Resource
+	SysPath   []string
+	SessionID string //session id, current context session id is used if empty
+	API       string //optional docker engine API endpoint, docker CLI is used if empty
+}
+
+//DockerCleanupResponse represents removed containers, networks, volumes and images
+type DockerCleanupResponse struct {
+	SessionID  string
+	Containers []string
+	Networks   []string
+	Volumes    []string
+	Images     []string
+}
+
+//Validate checks if request is valid
+func (r *DockerNetworkCreateRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("network name was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerNetworkRemoveRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("network name was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerVolumeCreateRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("volume name was empty for %v", r.Target.URL)
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *DockerVolumeRemoveRequest) Validate() error {
+	if r.Target == nil {
+		return fmt.Errorf("target was empty")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("volume name was empty for %v", r.Target.URL)
+	}
+	return nil
+}
@@ -9,4 +9,5 @@ type DockerPullRequest struct {
 	Target     *url.Resource
 	Repository string
 	Tag        string
+	API        string //optional docker engine API endpoint, docker CLI is used if empty
 }
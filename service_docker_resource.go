@@ -0,0 +1,216 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"path"
+	"strings"
+)
+
+//DockerScheme represents a container resource scheme: docker://<container>/path for local docker host or docker://<container>@<docker host:port>/path for remote one
+const DockerScheme = "docker"
+
+//DockerStagingDirectory represents a directory on the docker host where assets copied in and out of containers are staged
+const DockerStagingDirectory = "/tmp/endly/docker"
+
+//dockerResource represents a path inside a container
+type dockerResource struct {
+	Container string
+	Path      string
+	Host      *url.Resource //docker host ssh resource
+	listing   string        //container path listing of the last refresh
+}
+
+//IsDockerResource returns true if resource points inside a container
+func IsDockerResource(resource *url.Resource) bool {
+	return resource != nil && resource.ParsedURL != nil && resource.ParsedURL.Scheme == DockerScheme
+}
+
+//containerPath returns docker cp container path
+func (r *dockerResource) containerPath() string {
+	return r.Container + ":" + r.Path
+}
+
+//isDirectory returns true if container path denotes a directory, i.e. ends with /
+func (r *dockerResource) isDirectory() bool {
+	return strings.HasSuffix(r.Path, "/")
+}
+
+//stagingResource returns a staging resource on the docker host for supplied name
+func (r *dockerResource) stagingResource(context *Context, name string) *url.Resource {
+	var stagingPath = path.Join(DockerStagingDirectory, context.SessionID, name, r.Container, path.Base(r.Path))
+	if r.isDirectory() {
+		stagingPath += "/"
+	}
+	return url.NewResource(fmt.Sprintf("scp://%v%v", r.Host.ParsedURL.Host, stagingPath), r.Host.Credential)
+}
+
+func (r *dockerResource) stagingDirectory(staging *url.Resource) string {
+	return path.Dir(strings.TrimSuffix(staging.ParsedURL.Path, "/"))
+}
+
+//prepareStaging removes previous staging content and creates staging directory on the docker host
+func (r *dockerResource) prepareStaging(context *Context, staging *url.Resource) error {
+	if err := r.removeStaging(context, staging); err != nil {
+		return err
+	}
+	_, err := context.Execute(r.Host, fmt.Sprintf("mkdir -p %v", r.stagingDirectory(staging)))
+	return err
+}
+
+//removeStaging removes staging directory from the docker host, docker cp creates files as root so it runs as super user
+func (r *dockerResource) removeStaging(context *Context, staging *url.Resource) error {
+	_, err := context.ExecuteAsSuperUser(r.Host, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("rm -rf %v", r.stagingDirectory(staging)),
+			},
+		},
+	})
+	return err
+}
+
+func (r *dockerResource) copy(context *Context, source, target string) error {
+	_, err := context.ExecuteAsSuperUser(r.Host, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("docker cp %v %v", source, target),
+				Error:   []string{"Error", "No such container", "no such file", commandNotFound},
+			},
+		},
+	})
+	return err
+}
+
+//download copies container path into staging resource on the docker host
+func (r *dockerResource) download(context *Context, name string) (*url.Resource, error) {
+	var staging = r.stagingResource(context, name)
+	if err := r.prepareStaging(context, staging); err != nil {
+		return nil, err
+	}
+	if err := r.copy(context, r.containerPath(), strings.TrimSuffix(staging.ParsedURL.Path, "/")); err != nil {
+		return nil, err
+	}
+	return staging, nil
+}
+
+//listContainerPath returns container path long listing, used to detect changes without copying the content
+func (r *dockerResource) listContainerPath(context *Context) (string, error) {
+	response, err := context.ExecuteAsSuperUser(r.Host, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("docker exec %v ls -lR %v", r.Container, r.Path),
+				Error:   []string{"Error", "No such container", "No such file or directory", commandNotFound},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return response.Stdout(), nil
+}
+
+//refresh updates previously downloaded staging resource, container path is copied only if its listing changed since the last refresh,
+//the copy goes to a fresh temp directory first and its files are then renamed over the staged ones, so readers never see empty or partially copied staging content
+func (r *dockerResource) refresh(context *Context, staging *url.Resource) error {
+	listing, err := r.listContainerPath(context)
+	if err != nil {
+		return err
+	}
+	if listing == r.listing {
+		return nil
+	}
+	var stagingDirectory = r.stagingDirectory(staging)
+	var tempDirectory = stagingDirectory + ".refresh"
+	if _, err = context.ExecuteAsSuperUser(r.Host, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("rm -rf %v && mkdir -p %v", tempDirectory, tempDirectory),
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	if err = r.copy(context, r.containerPath(), path.Join(tempDirectory, path.Base(r.Path))); err != nil {
+		return err
+	}
+	if _, err = context.ExecuteAsSuperUser(r.Host, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("(cd %v && find . -type d -exec mkdir -p %v/{} \\; && find . -type f -exec mv -f {} %v/{} \\;) && rm -rf %v", tempDirectory, stagingDirectory, stagingDirectory, tempDirectory),
+				Error:   []string{"No such file or directory"},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	r.listing = listing
+	return nil
+}
+
+//upload copies staging resource from the docker host into container path
+func (r *dockerResource) upload(context *Context, staging *url.Resource) error {
+	if r.isDirectory() {
+		return r.copy(context, staging.ParsedURL.Path+".", r.containerPath())
+	}
+	return r.copy(context, staging.ParsedURL.Path, r.containerPath())
+}
+
+//stageContainerSource copies docker:// source from the container into the docker host staging directory, other resources are returned unchanged
+func stageContainerSource(context *Context, resource *url.Resource, stagingName string) (*url.Resource, *dockerResource, error) {
+	expandedResource, err := context.ExpandResource(resource)
+	if err != nil || !IsDockerResource(expandedResource) {
+		return resource, nil, err
+	}
+	container, err := newDockerResource(expandedResource)
+	if err != nil {
+		return nil, nil, err
+	}
+	staging, err := container.download(context, stagingName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to copy from container: %v, %v", expandedResource.URL, err)
+	}
+	return staging, container, nil
+}
+
+//stageContainerTarget returns the docker host staging resource for docker:// target, other resources are returned unchanged
+func stageContainerTarget(context *Context, resource *url.Resource, stagingName string) (*url.Resource, *dockerResource, error) {
+	expandedResource, err := context.ExpandResource(resource)
+	if err != nil || !IsDockerResource(expandedResource) {
+		return resource, nil, err
+	}
+	container, err := newDockerResource(expandedResource)
+	if err != nil {
+		return nil, nil, err
+	}
+	var staging = container.stagingResource(context, stagingName)
+	if err = container.prepareStaging(context, staging); err != nil {
+		return nil, nil, err
+	}
+	return staging, container, nil
+}
+
+//newDockerResource creates a container resource for supplied expanded docker:// resource, resource credential is used to connect to the docker host
+func newDockerResource(resource *url.Resource) (*dockerResource, error) {
+	var parsedURL = resource.ParsedURL
+	var result = &dockerResource{
+		Container: parsedURL.Host,
+		Path:      parsedURL.Path,
+	}
+	var host = "127.0.0.1:22"
+	if parsedURL.User != nil {
+		result.Container = parsedURL.User.Username()
+		host = parsedURL.Host
+		if !strings.Contains(host, ":") {
+			host += ":22"
+		}
+	}
+	if result.Container == "" {
+		return nil, fmt.Errorf("container was empty: %v", resource.URL)
+	}
+	if result.Path == "" {
+		result.Path = "/"
+	}
+	result.Host = url.NewResource(fmt.Sprintf("scp://%v/", host), resource.Credential)
+	return result, nil
+}
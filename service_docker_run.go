@@ -7,16 +7,25 @@ import (
 
 //DockerRunRequest represents a docker run request
 type DockerRunRequest struct {
-	SysPath     []string
-	Target      *url.Resource
-	Image       string
-	Port        string
-	Credentials map[string]string //container credential, i,e mysql password
-	Env         map[string]string
-	Mount       map[string]string
-	MappedPort  map[string]string
-	Params      map[string]string
-	Workdir     string
+	SysPath      []string
+	Target       *url.Resource
+	Image        string
+	Port         string
+	Credentials  map[string]string //container credential, i,e mysql password
+	Env          map[string]string
+	Mount        map[string]string
+	MappedPort   map[string]string
+	Params       map[string]string
+	Workdir      string
+	Network      string            //user defined network to connect container to
+	NetworkAlias string            //container alias within the network
+	Labels       map[string]string //container labels, endly session id label is always added
+	Restart      string            //restart policy, i.e. no, on-failure, always, unless-stopped
+	Memory       string            //memory limit, i.e. 512m, 1g
+	CPU          string            //number of CPUs, i.e. 0.5, 2
+	Entrypoint   string            //overrides image entrypoint
+	API          string            //optional docker engine API endpoint i.e. unix:///var/run/docker.sock or tcp://127.0.0.1:2375, if specified request is handled by the engine API instead of docker CLI
+	Wait         *DockerWait       //optional readiness criteria, run fails with the last container log lines if container is not ready within timeout
 }
 
 //Validate checks if request is valid
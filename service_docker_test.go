@@ -1,11 +1,24 @@
 package endly_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestDockerService_Images(t *testing.T) {
@@ -278,6 +291,70 @@ func TestDockerService_Run(t *testing.T) {
 	}
 }
 
+func TestDockerService_CaptureLogs(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var hostLogURL = "scp://127.0.0.1:22/tmp/endly/docker/test-session/logs/testMysql.log"
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload(hostLogURL, strings.NewReader("mysqld: ready for connections"))
+
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	target.Name = "testMysql"
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/docker/capture/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	context.SessionID = "test-session"
+	context.State().Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		context.Close()
+		return
+	}
+	var captureDirectory = path.Join(os.TempDir(), "endly_docker_cli_capture")
+	defer os.RemoveAll(captureDirectory)
+	serviceResponse := service.Run(context, &endly.DockerCaptureLogsRequest{Directory: captureDirectory})
+	assert.Equal(t, "", serviceResponse.Error)
+	serviceResponse = service.Run(context, &endly.DockerRunRequest{Target: target, Image: "mysql:5.6"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		assert.EqualValues(t, "scp://127.0.0.1:22/tmp/endly/docker/test-session/logs", context.State().GetMap(endly.DockerLogsStateKey).GetString("testMysql"))
+	}
+	//closing context kills the logs follower and then copies captured logs
+	context.Close()
+	var events = context.Events.Events
+	var killIndex, copyIndex = -1, -1
+	for i, event := range events {
+		switch value := event.Value["value"].(type) {
+		case *endly.ExecutionStartEvent:
+			if value.Stdin == "sudo kill 4242" {
+				killIndex = i
+			}
+		case *endly.CopyEventType:
+			if value.SourceURL == hostLogURL {
+				copyIndex = i
+			}
+		}
+	}
+	assert.True(t, killIndex != -1, "logs follower was not stopped")
+	assert.True(t, killIndex < copyIndex, "logs follower has to be stopped before logs are copied")
+	object, err := memStorage.StorageObject("file://" + path.Join(captureDirectory, "testMysql.log"))
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			defer reader.Close()
+			content, _ := ioutil.ReadAll(reader)
+			assert.EqualValues(t, "mysqld: ready for connections", string(content))
+		}
+	}
+}
+
 func TestDockerService_Command(t *testing.T) {
 	credentialFile, err := GetDummyCredential()
 	assert.Nil(t, err)
@@ -689,3 +766,769 @@ func TestDockerService_Remove(t *testing.T) {
 
 	}
 }
+
+//StartFakeDockerAPI starts a fake docker engine API on supplied unix socket, routes are keyed by method and path.
+//FakeDockerAPI represents docker engine API stub recording received requests
+type FakeDockerAPI struct {
+	Routes   map[string]string //response body keyed by method and path
+	Requests []string          //received requests as method and path
+	mutex    *sync.Mutex
+}
+
+//Deleted returns paths of received DELETE requests
+func (a *FakeDockerAPI) Deleted() []string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var result = make([]string, 0)
+	for _, request := range a.Requests {
+		if strings.HasPrefix(request, "DELETE ") {
+			result = append(result, strings.TrimPrefix(request, "DELETE "))
+		}
+	}
+	return result
+}
+
+//filterByLabels returns list body items matching label filters, list is either an array or an object with Volumes array
+func (a *FakeDockerAPI) filterByLabels(body string, filters string) string {
+	var criteria = make(map[string][]string)
+	if err := json.Unmarshal([]byte(filters), &criteria); err != nil || len(criteria["label"]) == 0 {
+		return body
+	}
+	var matches = func(item map[string]interface{}) bool {
+		labels, _ := item["Labels"].(map[string]interface{})
+		for _, label := range criteria["label"] {
+			var pair = strings.SplitN(label, "=", 2)
+			if value, has := labels[pair[0]]; !has || (len(pair) == 2 && value != pair[1]) {
+				return false
+			}
+		}
+		return true
+	}
+	var filter = func(items []map[string]interface{}) []map[string]interface{} {
+		var result = make([]map[string]interface{}, 0)
+		for _, item := range items {
+			if matches(item) {
+				result = append(result, item)
+			}
+		}
+		return result
+	}
+	var items = make([]map[string]interface{}, 0)
+	if err := json.Unmarshal([]byte(body), &items); err == nil {
+		filtered, _ := json.Marshal(filter(items))
+		return string(filtered)
+	}
+	var volumes = struct {
+		Volumes []map[string]interface{}
+	}{}
+	if err := json.Unmarshal([]byte(body), &volumes); err == nil {
+		volumes.Volumes = filter(volumes.Volumes)
+		filtered, _ := json.Marshal(volumes)
+		return string(filtered)
+	}
+	return body
+}
+
+func (a *FakeDockerAPI) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var key = request.Method + " " + request.URL.Path
+	a.mutex.Lock()
+	a.Requests = append(a.Requests, key)
+	a.mutex.Unlock()
+	body, has := a.Routes[key]
+	if !has {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"message":"No such container"}`))
+		return
+	}
+	if request.Method == "DELETE" {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if filters := request.URL.Query().Get("filters"); filters != "" {
+		body = a.filterByLabels(body, filters)
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write([]byte(body))
+}
+
+//StartRecordingFakeDockerAPI starts docker engine API stub on unix socket
+func StartRecordingFakeDockerAPI(socket string, routes map[string]string) (*FakeDockerAPI, func(), error) {
+	_ = os.Remove(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, nil, err
+	}
+	var api = &FakeDockerAPI{Routes: routes, Requests: make([]string, 0), mutex: &sync.Mutex{}}
+	var server = &http.Server{Handler: api}
+	go func() { _ = server.Serve(listener) }()
+	return api, func() { _ = server.Close() }, nil
+}
+
+func StartFakeDockerAPI(socket string, routes map[string]string) (func(), error) {
+	_, closer, err := StartRecordingFakeDockerAPI(socket, routes)
+	return closer, err
+}
+
+func TestDockerService_API(t *testing.T) {
+	var socket = path.Join(os.TempDir(), "endly_docker_test.sock")
+	closer, err := StartFakeDockerAPI(socket, map[string]string{
+		"GET /containers/json":       `[{"Id":"5b6c4e2f1a3d","Names":["/db1"],"Image":"mysql:5.6","Command":"docker-entrypoint.sh mysqld","State":"running","Status":"Up 2 minutes (healthy)","Ports":[{"IP":"0.0.0.0","PrivatePort":3306,"PublicPort":3306,"Type":"tcp"}],"Labels":{"endly":"test"}}]`,
+		"GET /images/json":           `[{"Id":"sha256:96dc914914f5a5b0e2","RepoTags":["mysql:5.6"],"Size":313524224}]`,
+		"POST /containers/create":    `{"Id":"5b6c4e2f1a3d"}`,
+		"POST /containers/db1/start": ``,
+		"GET /containers/db1/json":   `{"Id":"5b6c4e2f1a3d","Name":"/db1","Path":"docker-entrypoint.sh","Args":["mysqld"],"State":{"Status":"running","Running":true,"ExitCode":0,"Health":{"Status":"healthy"}},"Config":{"Image":"mysql:5.6","Labels":{"endly":"test"}},"NetworkSettings":{"Ports":{"3306/tcp":[{"HostIp":"0.0.0.0","HostPort":"3306"}]}}}`,
+		"POST /networks/create":      `{"Id":"7d86d31b1478"}`,
+		"GET /networks/net1":         `{"Id":"7d86d31b1478","Name":"net1","Driver":"bridge"}`,
+		"GET /networks":              `[{"Id":"7d86d31b1478","Name":"net1","Driver":"bridge"}]`,
+		"GET /volumes":               `{"Volumes":[{"Name":"fixtures","Driver":"local"}]}`,
+		"GET /containers/db1/logs":   "\x01\x00\x00\x00\x00\x00\x00\x16mysqld: ready for conn\x02\x00\x00\x00\x00\x00\x00\x0bwarning: x\n",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer closer()
+	var api = "unix://" + socket
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	target.Name = "db1"
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	serviceResponse := service.Run(context, &endly.DockerContainerStatusRequest{Target: target, API: api})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerContainerStatusResponse)
+		if assert.True(t, ok) && assert.EqualValues(t, 1, len(response.Containers)) {
+			var container = response.Containers[0]
+			assert.EqualValues(t, "db1", container.Names)
+			assert.EqualValues(t, "up", container.Status)
+			assert.EqualValues(t, "healthy", container.Health)
+			assert.EqualValues(t, "test", container.Labels["endly"])
+			assert.EqualValues(t, 3306, container.Ports[0].HostPort)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerImagesRequest{Target: target, API: api, Repository: "mysql"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerImagesResponse)
+		if assert.True(t, ok) && assert.EqualValues(t, 1, len(response.Images)) {
+			assert.EqualValues(t, "5.6", response.Images[0].Tag)
+			assert.EqualValues(t, "96dc914914f5", response.Images[0].ImageID)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerRunRequest{
+		Target:     target,
+		API:        api,
+		Image:      "mysql:5.6",
+		MappedPort: map[string]string{"3306": "3306"},
+		Env:        map[string]string{"MYSQL_ROOT_PASSWORD": "dev"},
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerContainerInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "up", info.Status)
+			assert.EqualValues(t, 0, info.ExitCode)
+			assert.EqualValues(t, "healthy", info.Health)
+			assert.EqualValues(t, "0.0.0.0:3306->3306/tcp", info.Port)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerContainerStartRequest{
+		Target: target,
+		API:    api,
+		Wait: &endly.DockerWait{
+			Health:     true,
+			LogPattern: "ready for conn",
+		},
+	})
+	assert.Equal(t, "", serviceResponse.Error)
+
+	serviceResponse = service.Run(context, &endly.DockerContainerStartRequest{
+		Target: target,
+		API:    api,
+		Wait: &endly.DockerWait{
+			LogPattern:  "never logged",
+			TimeoutMs:   200,
+			SleepTimeMs: 50,
+		},
+	})
+	assert.Contains(t, serviceResponse.Error, "was not ready")
+	assert.Contains(t, serviceResponse.Error, "warning: x")
+
+	serviceResponse = service.Run(context, &endly.DockerNetworkCreateRequest{Target: target, API: api, Name: "net1"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerNetworkInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "7d86d31b1478", info.ID)
+			assert.EqualValues(t, "bridge", info.Driver)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerLogsRequest{Target: target, API: api, Tail: 10})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerLogsResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "mysqld: ready for connwarning: x\n", response.Logs)
+		}
+	}
+
+	var captureDirectory = path.Join(os.TempDir(), "endly_docker_capture")
+	_ = os.RemoveAll(captureDirectory)
+	defer os.RemoveAll(captureDirectory)
+	serviceResponse = service.Run(context, &endly.DockerCaptureLogsRequest{Directory: captureDirectory})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	serviceResponse = service.Run(context, &endly.DockerRunRequest{Target: target, API: api, Image: "mysql:5.6"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		time.Sleep(100 * time.Millisecond)
+		content, err := ioutil.ReadFile(path.Join(captureDirectory, "db1.log"))
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, "mysqld: ready for connwarning: x\n", string(content))
+		}
+		assert.EqualValues(t, "file://"+captureDirectory, context.State().GetMap(endly.DockerLogsStateKey).GetString("db1"))
+	}
+}
+
+func TestDockerService_Cleanup(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/docker/cleanup/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.SessionID = "test-session"
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.DockerCleanupRequest{Target: target})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerCleanupResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "test-session", response.SessionID)
+			assert.EqualValues(t, []string{"5b6c4e2f1a3d", "8e9f0a1b2c3d"}, response.Containers)
+			assert.EqualValues(t, []string{"7d86d31b1478"}, response.Networks)
+			assert.EqualValues(t, []string{}, response.Volumes)
+			assert.EqualValues(t, []string{"7d9c5e1a2b3c"}, response.Images)
+		}
+	}
+	//resources labeled with other session are left intact
+	serviceResponse = service.Run(context, &endly.DockerCleanupRequest{Target: target, SessionID: "other-session"})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerCleanupResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "other-session", response.SessionID)
+			assert.EqualValues(t, 0, len(response.Containers))
+			assert.EqualValues(t, 0, len(response.Networks))
+			assert.EqualValues(t, 0, len(response.Volumes))
+			assert.EqualValues(t, 0, len(response.Images))
+		}
+	}
+}
+
+func TestDockerService_APICleanup(t *testing.T) {
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var sessionLabel = fmt.Sprintf(`{"%v":"%v"}`, endly.DockerSessionLabel, context.SessionID)
+	var otherLabel = fmt.Sprintf(`{"%v":"other-session"}`, endly.DockerSessionLabel)
+	var socket = path.Join(os.TempDir(), "endly_docker_cleanup_test.sock")
+	api, closer, err := StartRecordingFakeDockerAPI(socket, map[string]string{
+		"GET /containers/json":               `[{"Id":"5b6c4e2f1a3d","Names":["/db1"],"Labels":` + sessionLabel + `},{"Id":"8e9f0a1b2c3d","Names":["/other"],"Labels":` + otherLabel + `},{"Id":"1a2b3c4d5e6f","Names":["/unlabeled"]}]`,
+		"GET /networks":                      `[{"Id":"7d86d31b1478","Name":"net1","Labels":` + sessionLabel + `},{"Id":"9a8b7c6d5e4f","Name":"net2","Labels":` + otherLabel + `}]`,
+		"GET /volumes":                       `{"Volumes":[{"Name":"fixtures","Labels":` + sessionLabel + `},{"Name":"shared"}]}`,
+		"DELETE /containers/5b6c4e2f1a3d":    ``,
+		"DELETE /containers/8e9f0a1b2c3d":    ``,
+		"DELETE /containers/1a2b3c4d5e6f":    ``,
+		"DELETE /networks/7d86d31b1478":      ``,
+		"DELETE /networks/9a8b7c6d5e4f":      ``,
+		"DELETE /volumes/fixtures":           ``,
+		"DELETE /volumes/shared":             ``,
+		"GET /images/json":                   `[{"Id":"sha256:7d9c5e1a2b3c","RepoTags":["app:1.0"],"Labels":` + sessionLabel + `},{"Id":"sha256:96dc914914f5","RepoTags":["mysql:5.6"]}]`,
+		"DELETE /images/sha256:7d9c5e1a2b3c": ``,
+		"DELETE /images/sha256:96dc914914f5": ``,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer closer()
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.DockerCleanupRequest{Target: url.NewResource("scp://127.0.0.1:22/"), API: "unix://" + socket})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerCleanupResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, context.SessionID, response.SessionID)
+			assert.EqualValues(t, []string{"5b6c4e2f1a3d"}, response.Containers)
+			assert.EqualValues(t, []string{"net1"}, response.Networks)
+			assert.EqualValues(t, []string{"fixtures"}, response.Volumes)
+			assert.EqualValues(t, []string{"sha256:7d9c5e1a2b3c"}, response.Images)
+		}
+	}
+	assert.EqualValues(t, []string{"/containers/5b6c4e2f1a3d", "/networks/7d86d31b1478", "/volumes/fixtures", "/images/sha256:7d9c5e1a2b3c"}, api.Deleted())
+}
+
+func TestDockerService_ComposeRequest(t *testing.T) {
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, action := range []string{endly.DockerServiceComposeUpAction, endly.DockerServiceComposeDownAction, endly.DockerServiceComposePsAction, endly.DockerServiceComposeLogsAction} {
+		request, err := service.NewRequest(action)
+		assert.Nil(t, err, action)
+		assert.NotNil(t, request, action)
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	target.Name = "app"
+	request := &endly.DockerComposeRequest{
+		Target: target,
+	}
+	assert.NotNil(t, request.Validate())
+	request.Source = url.NewResource("test/docker/compose/docker-compose.yml")
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "app", request.ProjectName())
+	request.Project = "stack1"
+	assert.EqualValues(t, "stack1", request.ProjectName())
+}
+
+func TestDockerService_Compose(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/docker/compose/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	context.SessionID = "test-session"
+	memStorage := storage.NewMemoryService()
+	context.State().Put(endly.UseMemoryService, true)
+	context.State().Put("webPort", "8080")
+	err = memStorage.Upload("mem:///test/docker/compose/docker-compose.yml", strings.NewReader("version: '3'\nservices:\n  web:\n    image: nginx:1.13\n    ports:\n      - ${webPort}:80\n  db:\n    image: mysql:5.7\n"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	//files referenced by relative path are copied with the compose file
+	err = memStorage.Upload("mem:///test/docker/compose/nginx.conf", strings.NewReader("worker_processes 1;"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	//compose file on the docker host uses its directory as project directory
+	err = memStorage.Upload("file:///tmp/app/docker-compose.yml", strings.NewReader("version: '3'\nservices:\n  web:\n    image: nginx:1.13\n"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var request = endly.DockerComposeRequest{
+		Target:  target,
+		Source:  url.NewResource("mem:///test/docker/compose/docker-compose.yml"),
+		Project: "app",
+	}
+
+	upRequest := endly.DockerComposeUpRequest(request)
+	upRequest.Env = map[string]string{
+		"DB_PASSWORD": "it's secret",
+	}
+	serviceResponse := service.Run(context, &upRequest)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerComposeResponse)
+		if assert.True(t, ok) && assert.EqualValues(t, 2, len(response.Services)) {
+			assert.EqualValues(t, "up", response.Services["web"].Status)
+			assert.EqualValues(t, "app_web_1", response.Services["web"].Names)
+			assert.EqualValues(t, "healthy", response.Services["db"].Health)
+		}
+	}
+	object, err := memStorage.StorageObject("scp://127.0.0.1:22/tmp/endly/compose/app/docker-compose.yml")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.True(t, strings.Contains(string(content), "- 8080:80"), string(content))
+		}
+	}
+	//compose override labels services and default network with the session id
+	object, err = memStorage.StorageObject("scp://127.0.0.1:22/tmp/endly/compose/app/docker-compose.endly.json")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			var override = make(map[string]interface{})
+			err = json.NewDecoder(reader).Decode(&override)
+			_ = reader.Close()
+			if assert.Nil(t, err) {
+				var labels = map[string]interface{}{"endly.session": "test-session"}
+				assert.EqualValues(t, "3", override["version"])
+				assert.EqualValues(t, map[string]interface{}{
+					"web": map[string]interface{}{"labels": labels},
+					"db":  map[string]interface{}{"labels": labels},
+				}, override["services"])
+				assert.EqualValues(t, map[string]interface{}{
+					"default": map[string]interface{}{"labels": labels},
+				}, override["networks"])
+			}
+		}
+	}
+	object, err = memStorage.StorageObject("scp://127.0.0.1:22/tmp/endly/compose/app/nginx.conf")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.EqualValues(t, "worker_processes 1;", string(content))
+		}
+	}
+
+	psRequest := endly.DockerComposePsRequest(request)
+	psRequest.Services = []string{"db"}
+	serviceResponse = service.Run(context, &psRequest)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerComposeResponse)
+		if assert.True(t, ok) && assert.EqualValues(t, 1, len(response.Services)) {
+			assert.EqualValues(t, "9a8b7c6d5e4f", response.Services["db"].ContainerID)
+		}
+	}
+
+	logsRequest := endly.DockerComposeLogsRequest(request)
+	logsRequest.Source = url.NewResource("file:///tmp/app/docker-compose.yml")
+	logsRequest.Tail = 10
+	serviceResponse = service.Run(context, &logsRequest)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerComposeLogsResponse)
+		if assert.True(t, ok) {
+			assert.True(t, strings.Contains(response.Logs["db"], "ready for connections"), response.Logs["db"])
+			assert.True(t, strings.Contains(response.Logs["web"], "GET /"), response.Logs["web"])
+		}
+	}
+
+	downRequest := endly.DockerComposeDownRequest(request)
+	downRequest.RemoveVolumes = true
+	serviceResponse = service.Run(context, &downRequest)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerComposeResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "app", response.Project)
+			assert.EqualValues(t, 0, len(response.Services))
+		}
+	}
+	//compose-up registered compose-down, it runs before terminal session is closed
+	context.Close()
+}
+
+func TestDockerService_Build(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	registryCredential, err := GetCredential("registry", "deployer", "s3c'ret")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/docker/build/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.SessionID = "test-session"
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	serviceResponse := service.Run(context, &endly.DockerBuildRequest{
+		Target:     target,
+		Path:       "/tmp/app",
+		Repository: "app",
+		Tag:        "1.0",
+		BuildArgs:  map[string]string{"VERSION": "1.0"},
+		Labels:     map[string]string{"app.version": "1.0"},
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerImageInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "7d9c5e1a2b3c", info.ImageID)
+		}
+	}
+	var steps = make([]string, 0)
+	for _, event := range context.Events.Events {
+		if buildEvent, ok := event.Value["value"].(*endly.DockerBuildEvent); ok {
+			steps = append(steps, buildEvent.Step)
+		}
+	}
+	assert.EqualValues(t, []string{"Step 1/3 : FROM alpine:3.7", "Step 2/3 : COPY app /opt/app", "Step 3/3 : CMD [\"/opt/app/app\"]"}, steps)
+
+	serviceResponse = service.Run(context, &endly.DockerTagRequest{
+		Target:     target,
+		Source:     "app:1.0",
+		Repository: "registry.example.com/app",
+		Tag:        "1.0",
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerImageInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "registry.example.com/app", info.Repository)
+			assert.EqualValues(t, "7d9c5e1a2b3c", info.ImageID)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerLoginRequest{
+		Target:     target,
+		Registry:   "registry.example.com",
+		Credential: registryCredential,
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.DockerLoginResponse)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "deployer", response.Username)
+		}
+	}
+	for _, event := range context.Events.Events {
+		if executionEvent, ok := event.Value["value"].(*endly.ExecutionStartEvent); ok {
+			assert.False(t, strings.Contains(executionEvent.Stdin, "s3c"), executionEvent.Stdin)
+			if strings.Contains(executionEvent.Stdin, "docker login") {
+				assert.EqualValues(t, "printf '%s' '**docker**' | sudo docker login -u 'deployer' --password-stdin 'registry.example.com' 2>&1", executionEvent.Stdin)
+			}
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerPushRequest{
+		Target:     target,
+		Repository: "registry.example.com/app",
+		Tag:        "1.0",
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerImageInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "1.0", info.Tag)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.DockerPushRequest{
+		Target:     target,
+		Repository: "registry.example.com/private",
+		Tag:        "1.0",
+	})
+	assert.True(t, strings.Contains(serviceResponse.Error, "denied:"), serviceResponse.Error)
+}
+
+func TestDockerService_BuildRequest(t *testing.T) {
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, action := range []string{endly.DockerServiceBuildAction, endly.DockerServiceTagAction, endly.DockerServicePushAction, endly.DockerServiceLoginAction} {
+		request, err := service.NewRequest(action)
+		assert.Nil(t, err, action)
+		assert.NotNil(t, request, action)
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	buildRequest := &endly.DockerBuildRequest{Target: target, Path: "/tmp/app"}
+	assert.NotNil(t, buildRequest.Validate())
+	buildRequest.Repository = "app"
+	assert.Nil(t, buildRequest.Validate())
+
+	tagRequest := &endly.DockerTagRequest{Target: target, Source: "app:latest"}
+	assert.NotNil(t, tagRequest.Validate())
+	tagRequest.Repository = "registry.example.com/app"
+	assert.Nil(t, tagRequest.Validate())
+
+	loginRequest := &endly.DockerLoginRequest{Target: target, Registry: "registry.example.com"}
+	assert.NotNil(t, loginRequest.Validate())
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	serviceResponse := service.Run(context, loginRequest)
+	assert.Equal(t, "error", serviceResponse.Status)
+}
+
+func TestDockerService_WaitProbes(t *testing.T) {
+	var socket = path.Join(os.TempDir(), "endly_docker_wait_test.sock")
+	closer, err := StartFakeDockerAPI(socket, map[string]string{
+		"GET /containers/json":       `[{"Id":"5b6c4e2f1a3d","Names":["/db1"],"Image":"mysql:5.6","State":"running","Status":"Up 1 second"}]`,
+		"POST /containers/db1/start": ``,
+		"GET /containers/db1/json":   `{"Id":"5b6c4e2f1a3d","Name":"/db1","State":{"Status":"running","Running":true,"ExitCode":0}}`,
+		"POST /containers/db2/start": ``,
+		"GET /containers/db2/json":   `{"Id":"6c7d5f3a2b4e","Name":"/db2","State":{"Status":"exited","Running":false,"ExitCode":1}}`,
+		"GET /containers/db2/logs":   "\x02\x00\x00\x00\x00\x00\x00\x0dout of memory",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer closer()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = connection.Close()
+		}
+	}()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var api = "unix://" + socket
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var containerTarget = func(host, name string) *url.Resource {
+		var result = url.NewResource(fmt.Sprintf("scp://%v:22/", host))
+		result.Name = name
+		return result
+	}
+
+	//http probe is retried until service responds with status below 400
+	serviceResponse := service.Run(context, &endly.DockerContainerStartRequest{
+		Target: containerTarget("127.0.0.1", "db1"),
+		API:    api,
+		Wait: &endly.DockerWait{
+			TCPAddress:  listener.Addr().String(),
+			HTTPURL:     server.URL,
+			SleepTimeMs: 10,
+		},
+	})
+	assert.Equal(t, "", serviceResponse.Error)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	//closed port is reported once timeout elapses
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var closedAddress = closedListener.Addr().String()
+	_ = closedListener.Close()
+	serviceResponse = service.Run(context, &endly.DockerContainerStartRequest{
+		Target: containerTarget("127.0.0.1", "db1"),
+		API:    api,
+		Wait: &endly.DockerWait{
+			TCPAddress:  closedAddress,
+			TimeoutMs:   100,
+			SleepTimeMs: 10,
+		},
+	})
+	assert.Contains(t, serviceResponse.Error, "was not ready")
+	assert.Contains(t, serviceResponse.Error, "tcp probe "+closedAddress)
+
+	//exited container fails without waiting for the timeout
+	var startTime = time.Now()
+	serviceResponse = service.Run(context, &endly.DockerContainerStartRequest{
+		Target: containerTarget("127.0.0.1", "db2"),
+		API:    api,
+		Wait: &endly.DockerWait{
+			TCPAddress: closedAddress,
+		},
+	})
+	assert.Contains(t, serviceResponse.Error, "exited with code 1")
+	assert.Contains(t, serviceResponse.Error, "out of memory")
+	assert.True(t, time.Since(startTime) < 10*time.Second)
+
+	//tcp and http probes are refused for remote docker host
+	for _, request := range []*endly.DockerContainerStartRequest{
+		{Target: containerTarget("10.0.0.5", "db1"), Wait: &endly.DockerWait{TCPAddress: "127.0.0.1:3306"}},
+		{Target: containerTarget("127.0.0.1", "db1"), API: "tcp://10.0.0.5:2375", Wait: &endly.DockerWait{HTTPURL: "http://127.0.0.1:8080/"}},
+	} {
+		serviceResponse = service.Run(context, request)
+		assert.Contains(t, serviceResponse.Error, "not supported with remote docker host 10.0.0.5")
+	}
+}
+
+func TestDockerService_WaitCommand(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/docker/wait/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, url.NewResource("scp://127.0.0.1:22/", credentialFile), execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	service, err := context.Service(endly.DockerServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	target.Name = "db1"
+	//command probe is exec'd in the container until it exits with zero code
+	serviceResponse := service.Run(context, &endly.DockerContainerStartRequest{
+		Target:  target,
+		SysPath: []string{"/usr/local/bin"},
+		Wait: &endly.DockerWait{
+			Command:     "mysqladmin ping",
+			SleepTimeMs: 10,
+		},
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.DockerContainerInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, "up", info.Status)
+			assert.EqualValues(t, "db1", info.Names)
+		}
+	}
+
+	//exited container fails with its last log lines
+	target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	target.Name = "db2"
+	serviceResponse = service.Run(context, &endly.DockerContainerStartRequest{
+		Target:  target,
+		SysPath: []string{"/usr/local/bin"},
+		Wait: &endly.DockerWait{
+			Command:     "mysqladmin ping",
+			SleepTimeMs: 10,
+		},
+	})
+	assert.Contains(t, serviceResponse.Error, "db2 exited with code 1")
+	assert.Contains(t, serviceResponse.Error, "command 'mysqladmin ping' failed")
+	assert.Contains(t, serviceResponse.Error, "Cannot allocate memory")
+}
@@ -0,0 +1,57 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	url2 "net/url"
+)
+
+//DockerWait represents container readiness criteria, all specified probes have to succeed for a container to be ready.
+type DockerWait struct {
+	Health      bool   //wait for docker HEALTHCHECK healthy status
+	TCPAddress  string //TCP probe address i.e. 127.0.0.1:3306, probe connects from endly host, thus it is only supported with local docker host
+	HTTPURL     string //HTTP probe URL, response status code below 400 is expected, probe connects from endly host, thus it is only supported with local docker host
+	LogPattern  string //regular expression matched against container logs
+	Command     string //command exec'd in the container, zero exit code is expected
+	TimeoutMs   int    //readiness timeout, 60000 by default
+	SleepTimeMs int    //time between probes, 1000 by default
+	TailLines   int    //number of the last container log lines reported when readiness never arrives, 20 by default
+}
+
+//Init sets default values
+func (w *DockerWait) Init() {
+	if w.TimeoutMs == 0 {
+		w.TimeoutMs = 60000
+	}
+	if w.SleepTimeMs == 0 {
+		w.SleepTimeMs = 1000
+	}
+	if w.TailLines == 0 {
+		w.TailLines = 20
+	}
+}
+
+//Validate checks if probes can run against docker host, TCP and HTTP probes connect from endly host, so they are refused for remote docker host
+func (w *DockerWait) Validate(target *url.Resource, API string) error {
+	if w.TCPAddress == "" && w.HTTPURL == "" {
+		return nil
+	}
+	var hostname = target.ParsedURL.Hostname()
+	if target.ParsedURL.Scheme == "file" {
+		hostname = "localhost"
+	}
+	if API != "" {
+		endpoint, err := url2.Parse(API)
+		if err != nil {
+			return err
+		}
+		hostname = endpoint.Hostname()
+		if endpoint.Scheme == "unix" {
+			hostname = "localhost"
+		}
+	}
+	if hostname != "127.0.0.1" && hostname != "localhost" {
+		return fmt.Errorf("tcp and http probes connect from endly host, they are not supported with remote docker host %v, use command probe instead", hostname)
+	}
+	return nil
+}
@@ -66,6 +66,9 @@ func (s *execService) openSSHService(context *Context, request *OpenSessionReque
 	if err != nil {
 		return nil, err
 	}
+	if recorder := context.Recorder(); recorder.IsReplaying() {
+		return recorder.SSHService(target.Host())
+	}
 	var authConfig = &cred.Config{}
 	if target.Credential != "" {
 		err = authConfig.Load(target.Credential)
@@ -116,14 +119,18 @@ func (s *execService) openSession(context *Context, request *OpenSessionRequest)
 	}
 	sessions := context.TerminalSessions()
 
+	var sessionName = target.Host()
+	var commandsBasedir = request.CommandsBasedir
+	if recorder := context.Recorder(); commandsBasedir == "" && recorder.IsRecording() {
+		commandsBasedir = recorder.ExecDirectory(sessionName)
+	}
 	var replayCommands *ssh.ReplayCommands
-	if request.CommandsBasedir != "" {
-		replayCommands, err = ssh.NewReplayCommands(request.CommandsBasedir)
+	if commandsBasedir != "" {
+		replayCommands, err = ssh.NewReplayCommands(commandsBasedir)
 		if err != nil {
 			return nil, err
 		}
 	}
-	var sessionName = target.Host()
 	if sessions.Has(sessionName) {
 		session := sessions[sessionName]
 		err = s.initSession(context, target, session, request.Env)
@@ -336,6 +343,7 @@ func (s *execService) executeCommand(context *Context, session *SystemTerminalSe
 		var keys = toolbox.MapKeysToStringSlice(secure)
 		sort.Strings(keys)
 		for _, key := range keys {
+			cmd = strings.Replace(cmd, "'"+key+"'", quoteShellArgument(secure[key]), -1)
 			cmd = strings.Replace(cmd, key, secure[key], len(command))
 		}
 	}
@@ -462,6 +470,60 @@ func (s *execService) runCommands(context *Context, request *ExtractableCommandR
 	return response, nil
 }
 
+func (s *execService) runHostCommands(context *Context, request *ExtractableCommandRequest, target *url.Resource) (*CommandResponse, error) {
+	var hostRequest = &ExtractableCommandRequest{
+		Target:             target,
+		ExtractableCommand: request.ExtractableCommand,
+	}
+	if request.SuperUser {
+		superUserRequest := superUserCommandRequest{
+			Target:        target,
+			MangedCommand: request.ExtractableCommand,
+		}
+		var err error
+		if hostRequest, err = superUserRequest.AsCommandRequest(context); err != nil {
+			return nil, err
+		}
+	}
+	return s.runCommands(context, hostRequest)
+}
+
+func (s *execService) runMultiHostCommands(context *Context, request *ExtractableCommandRequest) (*MultiHostCommandResponse, error) {
+	if request.ExtractableCommand == nil {
+		return nil, fmt.Errorf("ExtractableCommand was empty")
+	}
+	targets, err := request.HostTargets(context)
+	if err != nil {
+		return nil, err
+	}
+	var batchSize = request.BatchSize
+	if batchSize <= 0 || batchSize > len(targets) {
+		batchSize = len(targets)
+	}
+	var response = NewMultiHostCommandResponse()
+	for i := 0; i < len(targets); i += batchSize {
+		var batchEnd = i + batchSize
+		if batchEnd > len(targets) {
+			batchEnd = len(targets)
+		}
+		var batch = targets[i:batchEnd]
+		group := sync.WaitGroup{}
+		group.Add(len(batch))
+		for _, target := range batch {
+			go func(hostContext *Context, target *url.Resource) {
+				defer group.Done()
+				commandResponse, err := s.runHostCommands(hostContext, request, target)
+				response.Add(target.Host(), commandResponse, err)
+			}(context.Clone(), target)
+		}
+		group.Wait()
+		if response.HasError() && !request.ContinueOnError {
+			return response, fmt.Errorf("failed to run command on: %v", strings.Join(toolbox.MapKeysToStringSlice(response.Errors), ","))
+		}
+	}
+	return response, nil
+}
+
 func (s *execService) closeSession(context *Context, request *CloseSessionRequest) (*CloseSessionResponse, error) {
 	clientSessions := context.TerminalSessions()
 	if session, has := clientSessions[request.SessionID]; has {
@@ -485,6 +547,11 @@ func (s *execService) Run(context *Context, request interface{}) *ServiceRespons
 	switch actualRequest := request.(type) {
 	case *CommandRequest:
 		var mangedCommandRequest = actualRequest.AsExtractableCommandRequest()
+		errorMessage = "failed to run command"
+		if mangedCommandRequest.IsMultiHost() {
+			response.Response, err = s.runMultiHostCommands(context, mangedCommandRequest)
+			break
+		}
 		if actualRequest.SuperUser {
 			superCommandRequest := superUserCommandRequest{
 				Target:        actualRequest.Target,
@@ -495,13 +562,16 @@ func (s *execService) Run(context *Context, request interface{}) *ServiceRespons
 		if err == nil {
 			response.Response, err = s.runCommands(context, mangedCommandRequest)
 		}
-		errorMessage = "failed to run command"
 
 	case *OpenSessionRequest:
 		response.Response, err = s.open(context, actualRequest)
 		errorMessage = fmt.Sprintf("failed to open session: %v", actualRequest.Target)
 	case *ExtractableCommandRequest:
-		response.Response, err = s.runCommands(context, actualRequest)
+		if actualRequest.IsMultiHost() {
+			response.Response, err = s.runMultiHostCommands(context, actualRequest)
+		} else {
+			response.Response, err = s.runCommands(context, actualRequest)
+		}
 		errorMessage = fmt.Sprintf("failed to run command: %v", actualRequest.ExtractableCommand)
 	case *superUserCommandRequest:
 		var commandRequest *ExtractableCommandRequest
@@ -540,11 +610,14 @@ func (s *execService) NewRequest(action string) (interface{}, error) {
 	return nil, fmt.Errorf("unsupported action: %v", action)
 }
 
-
 func isAmd64Architecture(candidate string) bool {
 	return strings.Contains(candidate, "amd64") || strings.Contains(candidate, "x86_64")
 }
 
+func isArm64Architecture(candidate string) bool {
+	return strings.Contains(candidate, "arm64") || strings.Contains(candidate, "aarch64")
+}
+
 func (s *execService) detectOperatingSystem(session *SystemTerminalSession) (*OperatingSystem, error) {
 	operatingSystem := &OperatingSystem{
 		Path: &SystemPath{
@@ -590,6 +663,8 @@ func (s *execService) detectOperatingSystem(session *SystemTerminalSession) (*Op
 	}
 	if isAmd64Architecture(operatingSystem.Hardware) {
 		operatingSystem.Architecture = "amd64"
+	} else if isArm64Architecture(operatingSystem.Hardware) {
+		operatingSystem.Architecture = "arm64"
 	}
 	operatingSystem.System = session.System()
 	output, err = session.Run("echo $PATH", 0)
@@ -2,6 +2,7 @@ package endly
 
 import (
 	"fmt"
+	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/url"
 )
 
@@ -22,7 +23,7 @@ type ExecutionOptions struct {
 
 //Execution represents an execution instructions
 type Execution struct {
-	Credentials map[string]string //actual secured credential details as map { '**mysql**': 'path to credentail' }like password, etc..., if secure is not empty it will replace **** in command just before execution
+	Credentials map[string]string //actual secured credential details as map { '**mysql**': 'path to credentail' }like password, etc..., if secure is not empty it will replace **** in command just before execution, single quoted '**mysql**' is replaced with shell quoted secret
 	MatchOutput string            //only run this execution is output from a previous command is matched
 	Command     string            //command to be executed
 	Extraction  DataExtractions   //Stdout data extraction instruction
@@ -35,19 +36,27 @@ type ExtractableCommandRequest struct {
 	SuperUser          bool                ///flag to run it as super user
 	Target             *url.Resource       //target destination where to run a command.
 	ExtractableCommand *ExtractableCommand //managed command
+	Targets            []*url.Resource     //optional list of targets to run command on, if specified response is MultiHostCommandResponse
+	HostGroup          string              //optional state key of a host group defined in workflow data i.e. data.cluster, group item is either URL or resource map
+	BatchSize          int                 //rolling batch size for multi host execution, 0 runs command concurrently on all hosts
+	ContinueOnError    bool                //flag to continue with remaining batches when command fails on any host
 }
 
 //CommandRequest represents a simple command
 type CommandRequest struct {
-	SuperUser bool          //flag is command needs to run as super suer
-	Target    *url.Resource //target destination where to run a command.
-	Commands  []string      //list of commands to run
-	TimeoutMs int
+	SuperUser       bool          //flag is command needs to run as super suer
+	Target          *url.Resource //target destination where to run a command.
+	Commands        []string      //list of commands to run
+	TimeoutMs       int
+	Targets         []*url.Resource //optional list of targets to run command on, if specified response is MultiHostCommandResponse
+	HostGroup       string          //optional state key of a host group defined in workflow data i.e. data.cluster, group item is either URL or resource map
+	BatchSize       int             //rolling batch size for multi host execution, 0 runs command concurrently on all hosts
+	ContinueOnError bool            //flag to continue with remaining batches when command fails on any host
 }
 
 //Validate validates managed command request
 func (r *ExtractableCommandRequest) Validate() error {
-	if r.Target == nil {
+	if r.Target == nil && !r.IsMultiHost() {
 		return fmt.Errorf("Target was empty")
 	}
 	if r.ExtractableCommand == nil {
@@ -56,6 +65,65 @@ func (r *ExtractableCommandRequest) Validate() error {
 	return nil
 }
 
+//IsMultiHost returns true if command has to run on multiple hosts
+func (r *ExtractableCommandRequest) IsMultiHost() bool {
+	return len(r.Targets) > 0 || r.HostGroup != ""
+}
+
+//HostTargets returns expanded targets for multi host execution, host without credential inherits Target credential, targets sharing the same host and port are rejected.
+func (r *ExtractableCommandRequest) HostTargets(context *Context) ([]*url.Resource, error) {
+	var result = make([]*url.Resource, 0)
+	var credential = ""
+	if r.Target != nil {
+		credential = r.Target.Credential
+	}
+	var hosts = make(map[string]string)
+	var appendTarget = func(target *url.Resource) error {
+		if target.Credential == "" {
+			target = url.NewResource(target.URL, credential)
+		}
+		expanded, err := context.ExpandResource(target)
+		if err != nil {
+			return err
+		}
+		if URL, has := hosts[expanded.Host()]; has {
+			return fmt.Errorf("duplicate host targets: %v, %v, terminal session is shared per host", URL, expanded.URL)
+		}
+		hosts[expanded.Host()] = expanded.URL
+		result = append(result, expanded)
+		return nil
+	}
+	for _, target := range r.Targets {
+		if err := appendTarget(target); err != nil {
+			return nil, err
+		}
+	}
+	if r.HostGroup != "" {
+		var state = context.State()
+		group, has := state.GetValue(r.HostGroup)
+		if !has || !toolbox.IsSlice(group) {
+			return nil, fmt.Errorf("failed to lookup host group: %v, expected a list", r.HostGroup)
+		}
+		for _, item := range toolbox.AsSlice(group) {
+			var target = &url.Resource{}
+			if toolbox.IsMap(item) {
+				if err := converter.AssignConverted(target, item); err != nil {
+					return nil, fmt.Errorf("invalid host group %v item: %v, %v", r.HostGroup, item, err)
+				}
+			} else {
+				target.URL = toolbox.AsString(item)
+			}
+			if err := appendTarget(target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("host targets were empty")
+	}
+	return result, nil
+}
+
 //AsExtractableCommandRequest returns ExtractableCommandRequest for this requests
 func (r *CommandRequest) AsExtractableCommandRequest() *ExtractableCommandRequest {
 	var extractableCommand = &ExtractableCommand{
@@ -75,6 +143,10 @@ func (r *CommandRequest) AsExtractableCommandRequest() *ExtractableCommandReques
 		SuperUser:          r.SuperUser,
 		Target:             r.Target,
 		ExtractableCommand: extractableCommand,
+		Targets:            r.Targets,
+		HostGroup:          r.HostGroup,
+		BatchSize:          r.BatchSize,
+		ContinueOnError:    r.ContinueOnError,
 	}
 }
 
@@ -3,6 +3,7 @@ package endly
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
 //CommandLog represents an executed command with Stdin, Stdout or Error
@@ -67,3 +68,38 @@ func NewCommandLog(stdin, stdout string, err error) *CommandLog {
 	}
 	return result
 }
+
+//MultiHostCommandResponse represents command responses and errors keyed by target host.
+type MultiHostCommandResponse struct {
+	Responses map[string]*CommandResponse
+	Errors    map[string]string
+	mutex     *sync.Mutex
+}
+
+//Add adds host command response or error
+func (r *MultiHostCommandResponse) Add(host string, response *CommandResponse, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if response != nil {
+		r.Responses[host] = response
+	}
+	if err != nil {
+		r.Errors[host] = fmt.Sprintf("%v", err)
+	}
+}
+
+//HasError returns true if command failed on any host
+func (r *MultiHostCommandResponse) HasError() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.Errors) > 0
+}
+
+//NewMultiHostCommandResponse creates a new MultiHostCommandResponse
+func NewMultiHostCommandResponse() *MultiHostCommandResponse {
+	return &MultiHostCommandResponse{
+		Responses: make(map[string]*CommandResponse),
+		Errors:    make(map[string]string),
+		mutex:     &sync.Mutex{},
+	}
+}
@@ -3,7 +3,9 @@ package endly_test
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/url"
+	"strings"
 	"testing"
 )
 
@@ -60,6 +62,146 @@ func Test_NewSimpleCommandRequest(t *testing.T) {
 	assert.EqualValues(t, "ls -al", command.ExtractableCommand.Executions[0].Command)
 }
 
+func TestExtractableCommandRequest_HostTargets(t *testing.T) {
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	var state = context.State()
+	state.Put("data", map[string]interface{}{
+		"cluster": []interface{}{
+			"ssh://10.0.0.1:22/",
+			map[string]interface{}{
+				"URL":        "ssh://10.0.0.2:22/",
+				"Credential": "/tmp/other.json",
+			},
+		},
+	})
+	request := &endly.CommandRequest{
+		Target:    url.NewResource("ssh://127.0.0.1:22/", "/tmp/default.json"),
+		Targets:   []*url.Resource{url.NewResource("ssh://10.0.0.3:22/")},
+		HostGroup: "data.cluster",
+		Commands:  []string{"ls -al"},
+	}
+	commandRequest := request.AsExtractableCommandRequest()
+	assert.True(t, commandRequest.IsMultiHost())
+	targets, err := commandRequest.HostTargets(context)
+	if assert.Nil(t, err) && assert.EqualValues(t, 3, len(targets)) {
+		assert.EqualValues(t, "10.0.0.3:22", targets[0].Host())
+		assert.EqualValues(t, "/tmp/default.json", targets[0].Credential)
+		assert.EqualValues(t, "10.0.0.1:22", targets[1].Host())
+		assert.EqualValues(t, "/tmp/default.json", targets[1].Credential)
+		assert.EqualValues(t, "10.0.0.2:22", targets[2].Host())
+		assert.EqualValues(t, "/tmp/other.json", targets[2].Credential)
+	}
+
+	commandRequest.Targets = append(commandRequest.Targets, url.NewResource("ssh://deployer@10.0.0.3:22/"))
+	_, err = commandRequest.HostTargets(context)
+	assert.NotNil(t, err)
+
+	commandRequest.Targets = commandRequest.Targets[:1]
+	commandRequest.HostGroup = "data.missing"
+	_, err = commandRequest.HostTargets(context)
+	assert.NotNil(t, err)
+}
+
+func TestExecService_MultiHost(t *testing.T) {
+	var credentialFile, err = GetDummyCredential()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var hosts = map[string]string{
+		"127.0.0.1:22": "test/exec/multihost/host1",
+		"127.0.0.2:22": "test/exec/multihost/host2",
+		"127.0.0.3:22": "test/exec/multihost/host3", //deploy.sh is missing
+	}
+	var targets = func(hosts ...string) []*url.Resource {
+		var result = make([]*url.Resource, 0)
+		for _, host := range hosts {
+			result = append(result, url.NewResource("scp://"+host+"/", credentialFile))
+		}
+		return result
+	}
+	var useCases = []struct {
+		description     string
+		targets         []*url.Resource
+		batchSize       int
+		continueOnError bool
+		hasError        bool
+		succeeded       []string
+		failed          []string
+	}{
+		{
+			description: "concurrent",
+			targets:     targets("127.0.0.1:22", "127.0.0.2:22"),
+			succeeded:   []string{"127.0.0.1:22", "127.0.0.2:22"},
+		},
+		{
+			description: "batch failing in the last batch",
+			targets:     targets("127.0.0.1:22", "127.0.0.2:22", "127.0.0.3:22"),
+			batchSize:   2,
+			hasError:    true,
+			succeeded:   []string{"127.0.0.1:22", "127.0.0.2:22"},
+			failed:      []string{"127.0.0.3:22"},
+		},
+		{
+			description: "fail fast skips remaining batches",
+			targets:     targets("127.0.0.3:22", "127.0.0.1:22", "127.0.0.2:22"),
+			batchSize:   1,
+			hasError:    true,
+			failed:      []string{"127.0.0.3:22"},
+		},
+		{
+			description:     "continue on error",
+			targets:         targets("127.0.0.3:22", "127.0.0.1:22", "127.0.0.2:22"),
+			batchSize:       1,
+			continueOnError: true,
+			succeeded:       []string{"127.0.0.1:22", "127.0.0.2:22"},
+			failed:          []string{"127.0.0.3:22"},
+		},
+	}
+	for _, useCase := range useCases {
+		manager := endly.NewManager()
+		context := manager.NewContext(toolbox.NewContext())
+		service, err := context.Service(endly.ExecServiceID)
+		if !assert.Nil(t, err) {
+			return
+		}
+		for host, baseDir := range hosts {
+			replayService, err := GetReplayService(baseDir)
+			if !assert.Nil(t, err) {
+				return
+			}
+			response := service.Run(context, &endly.OpenSessionRequest{
+				Target:        url.NewResource("scp://"+host+"/", credentialFile),
+				ReplayService: replayService,
+			})
+			if !assert.Equal(t, "", response.Error, useCase.description) {
+				return
+			}
+		}
+		serviceResponse := service.Run(context, &endly.CommandRequest{
+			Targets:         useCase.targets,
+			BatchSize:       useCase.batchSize,
+			ContinueOnError: useCase.continueOnError,
+			Commands:        []string{"deploy.sh"},
+		})
+		assert.Equal(t, useCase.hasError, serviceResponse.Error != "", useCase.description)
+		response, ok := serviceResponse.Response.(*endly.MultiHostCommandResponse)
+		if assert.True(t, ok, useCase.description) {
+			assert.Equal(t, len(useCase.succeeded), len(response.Responses), useCase.description)
+			for _, host := range useCase.succeeded {
+				if assert.NotNil(t, response.Responses[host], useCase.description+" "+host) {
+					assert.True(t, strings.Contains(response.Responses[host].Stdout(), "deployed"), useCase.description+" "+host)
+				}
+			}
+			assert.Equal(t, len(useCase.failed), len(response.Errors), useCase.description)
+			for _, host := range useCase.failed {
+				assert.True(t, strings.Contains(response.Errors[host], "command not found"), useCase.description+" "+host)
+			}
+		}
+		context.Close()
+	}
+}
+
 // Function template  to capture SSH conversation
 //func TestXXXXService_Run(t *testing.T) {
 //
@@ -214,6 +214,9 @@ func (s *httpRunnerService) send(context *Context, request *SendHTTPRequest) (*S
 	if err != nil {
 		return nil, fmt.Errorf("failed to send req: %v", err)
 	}
+	if recorder := context.Recorder(); recorder != nil {
+		client.Transport = recorder.Transport(client.Transport)
+	}
 	var result = &SendHTTPResponse{
 		Responses: make([]*HTTPResponse, 0),
 		Extracted: make(map[string]string),
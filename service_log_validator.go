@@ -29,6 +29,8 @@ const (
 
 	//LogValidatorServiceResetAction represents verification pending logs reset action
 	LogValidatorServiceResetAction = "reset"
+
+	logValidatorStagingName = "log"
 )
 
 type logValidatorService struct {
@@ -477,7 +479,11 @@ func (s *logValidatorService) getStorageService(context *Context, resource *url.
 }
 
 func (s *logValidatorService) listenForChanges(context *Context, request *LogValidatorListenRequest) error {
-	var target, err = context.ExpandResource(request.Source)
+	var source, container, err = stageContainerSource(context, request.Source, logValidatorStagingName)
+	if err != nil {
+		return err
+	}
+	target, err := context.ExpandResource(source)
 	if err != nil {
 		return err
 	}
@@ -492,7 +498,13 @@ func (s *logValidatorService) listenForChanges(context *Context, request *LogVal
 			frequency = 400 * time.Millisecond
 		}
 		for !context.IsClosed() {
-			_, err := s.readLogFiles(context, service, request.Source, request.Types...)
+			if container != nil {
+				if err := container.refresh(context, source); err != nil {
+					log.Printf("failed to copy logs from container %v", err)
+					break
+				}
+			}
+			_, err := s.readLogFiles(context, service, source, request.Types...)
 			if err != nil {
 				log.Printf("failed to load log types %v", err)
 				break
@@ -515,12 +527,16 @@ func (s *logValidatorService) listen(context *Context, request *LogValidatorList
 			return nil, fmt.Errorf("listener has been already register for %v", logType.Name)
 		}
 	}
-	service, err := storage.NewServiceForURL(request.Source.URL, request.Source.Credential)
+	stagedSource, _, err := stageContainerSource(context, source, logValidatorStagingName)
+	if err != nil {
+		return nil, err
+	}
+	service, err := storage.NewServiceForURL(stagedSource.URL, stagedSource.Credential)
 	if err != nil {
 		return nil, err
 	}
 	defer service.Close()
-	logTypeMetas, err := s.readLogFiles(context, service, request.Source, request.Types...)
+	logTypeMetas, err := s.readLogFiles(context, service, stagedSource, request.Types...)
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,13 @@ package endly
 import (
 	"fmt"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"net"
+	"net/http"
+	"path"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,6 +30,8 @@ const ProcessServiceStopAllAction = "stop-all"
 
 type processService struct {
 	*AbstractService
+	mutex   *sync.RWMutex
+	watched map[string]bool
 }
 
 func (s *processService) Run(context *Context, request interface{}) *ServiceResponse {
@@ -142,7 +150,26 @@ func (s *processService) checkProcess(context *Context, request *ProcessStatusRe
 	return response, nil
 }
 
+func watchKey(target *url.Resource, pid int) string {
+	return fmt.Sprintf("%v:%v", target.Host(), pid)
+}
+
+func (s *processService) isWatched(key string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.watched[key]
+}
+
+func (s *processService) unwatch(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.watched, key)
+}
+
 func (s *processService) stopProcess(context *Context, request *ProcessStopRequest) (*CommandResponse, error) {
+	if target, err := context.ExpandResource(request.Target); err == nil {
+		s.unwatch(watchKey(target, request.Pid))
+	}
 	commandResult, err := context.ExecuteAsSuperUser(request.Target, &ExtractableCommand{
 		Executions: []*Execution{
 			{
@@ -156,21 +183,192 @@ func (s *processService) stopProcess(context *Context, request *ProcessStopReque
 	return commandResult, err
 }
 
-func indexProcesses(processes ...*ProcessInfo) map[int]*ProcessInfo {
-	var result = make(map[int]*ProcessInfo)
-	for _, process := range processes {
-		result[process.Pid] = process
+//isRunning checks if process with supplied pid is running
+func (s *processService) isRunning(context *Context, target *url.Resource, pid int) (bool, error) {
+	commandResponse, err := context.Execute(target, fmt.Sprintf("ps -p %v -o pid=", pid))
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(commandResponse.Stdout(), "\n") {
+		if strings.TrimSpace(line) == toolbox.AsString(pid) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//launch starts process in the background, it returns pid of the started process
+func (s *processService) launch(context *Context, request *ProcessStartRequest) (int, error) {
+	var startCommand = request.Command + " " + strings.Join(request.Arguments, " ")
+	if request.ImmuneToHangups {
+		startCommand = fmt.Sprintf("nohup  %v", startCommand)
+	}
+	if stdout := context.Expand(request.Stdout); stdout != "" {
+		startCommand += " > " + stdout
+	}
+	if stderr := context.Expand(request.Stderr); stderr != "" {
+		startCommand += " 2> " + stderr
+	} else if request.Stdout != "" {
+		startCommand += " 2>&1"
+	}
+	commandResponse, err := context.Execute(request.Target, &ExtractableCommand{
+		Options: request.Options,
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("cd %v ", request.Directory),
+			},
+			{
+				Command: startCommand + " & echo \"pid:$!\"",
+				Extraction: []*DataExtraction{
+					{
+						RegExpr: "pid:(\\d+)",
+						Key:     "pid",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	pid, ok := commandResponse.Extracted["pid"]
+	if !ok {
+		return 0, fmt.Errorf("failed to determine pid of %v", request.Command)
+	}
+	return toolbox.AsInt(pid), nil
+}
+
+//checkReadiness returns an error if any of the readiness checks does not pass
+func (s *processService) checkReadiness(context *Context, target *url.Resource, readiness *ProcessReadiness, logPattern *regexp.Regexp, logResource *url.Resource, timeout time.Duration) error {
+	if readiness.Port > 0 {
+		connection, err := net.DialTimeout("tcp", net.JoinHostPort(target.ParsedURL.Hostname(), toolbox.AsString(readiness.Port)), timeout)
+		if err != nil {
+			return err
+		}
+		connection.Close()
+	}
+	if readiness.URL != "" {
+		var client = &http.Client{Timeout: timeout}
+		response, err := client.Get(context.Expand(readiness.URL))
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode >= 400 {
+			return fmt.Errorf("%v responded with status %v", readiness.URL, response.StatusCode)
+		}
+	}
+	if logPattern != nil {
+		content, err := downloadContent(context, logResource)
+		if err != nil {
+			return err
+		}
+		if !logPattern.Match(content) {
+			return fmt.Errorf("%v was not matched in %v", readiness.LogPattern, logResource.URL)
+		}
+	}
+	return nil
+}
+
+//waitUntilReady waits until process passes readiness checks, it fails if process exits or timeout is exceeded
+func (s *processService) waitUntilReady(context *Context, target *url.Resource, request *ProcessStartRequest, pid int) error {
+	var readiness = request.Readiness
+	var timeoutMs = readiness.TimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	var frequency = time.Duration(readiness.FrequencyMs) * time.Millisecond
+	if readiness.FrequencyMs <= 0 {
+		frequency = 500 * time.Millisecond
+	}
+	var logPattern *regexp.Regexp
+	var logResource *url.Resource
+	if readiness.LogPattern != "" {
+		var err error
+		if logPattern, err = regexp.Compile(context.Expand(readiness.LogPattern)); err != nil {
+			return fmt.Errorf("invalid readiness log pattern: %v", err)
+		}
+		var logFile = context.Expand(readiness.LogFile)
+		if logFile == "" {
+			logFile = context.Expand(request.Stdout)
+		}
+		if !path.IsAbs(logFile) {
+			logFile = path.Join(context.Expand(request.Directory), logFile)
+		}
+		logResource = url.NewResource(fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, logFile), target.Credential)
+	}
+	var deadline = time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		running, err := s.isRunning(context, target, pid)
+		if err != nil {
+			return err
+		}
+		if !running {
+			return fmt.Errorf("process %v exited before it was ready", pid)
+		}
+		err = s.checkReadiness(context, target, readiness, logPattern, logResource, frequency)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("process %v was not ready within %v ms, %v", pid, timeoutMs, err)
+		}
+		time.Sleep(frequency)
 	}
-	return result
+}
+
+//watch restarts process if it exits until the context is closed or the process is stopped with the service, watch runs commands on its own terminal session, each restart emits ProcessRestartEventType to the parent context events
+func (s *processService) watch(context *Context, target *url.Resource, request *ProcessStartRequest, pid int) {
+	var key = watchKey(target, pid)
+	s.mutex.Lock()
+	s.watched[key] = true
+	s.mutex.Unlock()
+	var watchContext = context.Clone()
+	watchContext.MakeSessionsAsyncSafe()
+	var frequency = time.Duration(request.Watch.FrequencyMs) * time.Millisecond
+	if request.Watch.FrequencyMs <= 0 {
+		frequency = time.Second
+	}
+	go func() {
+		var restarts = 0
+		for !watchContext.IsClosed() && s.isWatched(key) {
+			time.Sleep(frequency)
+			running, err := s.isRunning(watchContext, target, pid)
+			if err != nil || running || watchContext.IsClosed() || !s.isWatched(key) {
+				continue
+			}
+			restarts++
+			var event = &ProcessRestartEventType{Command: request.Command, Pid: pid, Restarts: restarts}
+			s.unwatch(key)
+			event.NewPid, err = s.launch(watchContext, request)
+			if err != nil {
+				event.Error = err.Error()
+			}
+			AddEvent(watchContext, event, Pairs("value", event), Info)
+			if err != nil || (request.Watch.MaxRestarts > 0 && restarts >= request.Watch.MaxRestarts) {
+				return
+			}
+			pid = event.NewPid
+			key = watchKey(target, pid)
+			s.mutex.Lock()
+			s.watched[key] = true
+			s.mutex.Unlock()
+		}
+	}()
 }
 
 func (s *processService) startProcess(context *Context, request *ProcessStartRequest) (*ProcessStartResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
 	origProcesses, err := s.checkProcess(context, &ProcessStatusRequest{
 		Target:  request.Target,
 		Command: request.Command,
 	})
-
-	var result = &ProcessStartResponse{}
 	if err != nil {
 		return nil, err
 	}
@@ -185,27 +383,20 @@ func (s *processService) startProcess(context *Context, request *ProcessStartReq
 			}
 		}
 	}
-	changeDirCommand := fmt.Sprintf("cd %v ", request.Directory)
-
-	var startCommand = request.Command + " " + strings.Join(request.Arguments, " ") + " &"
-	if request.ImmuneToHangups {
-		startCommand = fmt.Sprintf("nohup  %v", startCommand)
-	}
-	_, err = context.Execute(request.Target, &ExtractableCommand{
-		Options: request.Options,
-		Executions: []*Execution{
-			{
-				Command: changeDirCommand,
-			},
-			{
-				Command: startCommand,
-			},
-		},
-	})
+	pid, err := s.launch(context, request)
 	if err != nil {
 		return nil, err
 	}
-	time.Sleep(time.Second)
+	var result = &ProcessStartResponse{
+		Command: request.Command,
+		Pid:     pid,
+		Info:    make([]*ProcessInfo, 0),
+	}
+	if request.Readiness != nil {
+		if err = s.waitUntilReady(context, target, request, pid); err != nil {
+			return nil, err
+		}
+	}
 	newProcesses, err := s.checkProcess(context, &ProcessStatusRequest{
 		Target:  request.Target,
 		Command: request.Command,
@@ -213,16 +404,14 @@ func (s *processService) startProcess(context *Context, request *ProcessStartReq
 	if err != nil {
 		return nil, err
 	}
-
-	result.Info = make([]*ProcessInfo, 0)
-	existingProcesses := indexProcesses(origProcesses.Processes...)
-
 	for _, candidate := range newProcesses.Processes {
-		if _, has := existingProcesses[candidate.Pid]; !has {
+		if candidate.Pid == pid {
 			result.Info = append(result.Info, candidate)
-			break
 		}
 	}
+	if request.Watch != nil {
+		s.watch(context, target, request, pid)
+	}
 	return result, nil
 }
 
@@ -249,6 +438,8 @@ func NewProcessService() Service {
 			ProcessServiceStatusAction,
 			ProcessServiceStopAction,
 			ProcessServiceStopAllAction),
+		mutex:   &sync.RWMutex{},
+		watched: make(map[string]bool),
 	}
 	result.AbstractService.Service = result
 	return result
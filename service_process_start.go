@@ -1,6 +1,9 @@
 package endly
 
-import "github.com/viant/toolbox/url"
+import (
+	"errors"
+	"github.com/viant/toolbox/url"
+)
 
 //ProcessStartRequest represents a start request
 type ProcessStartRequest struct {
@@ -9,11 +12,52 @@ type ProcessStartRequest struct {
 	Options         *ExecutionOptions
 	Directory       string
 	Arguments       []string
-	ImmuneToHangups bool //start process as nohup
+	ImmuneToHangups bool              //start process as nohup
+	Stdout          string            //file process stdout is redirected to, relative path is resolved against Directory
+	Stderr          string            //file process stderr is redirected to, Stdout file by default
+	Readiness       *ProcessReadiness //optional readiness probe
+	Watch           *ProcessWatch     //optional watch restarting process if it exits during the workflow
+}
+
+//ProcessReadiness represents process readiness probe, process is ready once all specified checks pass
+type ProcessReadiness struct {
+	Port        int    //port accepting TCP connections on target host
+	URL         string //HTTP URL responding with status code below 400
+	LogPattern  string //regular expression matched in LogFile
+	LogFile     string //file checked with LogPattern, Stdout file by default
+	TimeoutMs   int    //max time to wait for readiness, 30 sec by default
+	FrequencyMs int    //check frequency, 500 ms by default
+}
+
+//ProcessWatch represents process watch settings
+type ProcessWatch struct {
+	FrequencyMs int //check frequency, 1 sec by default
+	MaxRestarts int //max number of restarts, 0 means no limit
+}
+
+//ProcessRestartEventType represents an event emitted when watched process exited and was restarted
+type ProcessRestartEventType struct {
+	Command  string
+	Pid      int //exited process pid
+	NewPid   int //restarted process pid
+	Restarts int
+	Error    string
+}
+
+//Validate checks if request is valid
+func (r *ProcessStartRequest) Validate() error {
+	if r.Command == "" {
+		return errors.New("command was empty")
+	}
+	if r.Readiness != nil && r.Readiness.LogPattern != "" && r.Readiness.LogFile == "" && r.Stdout == "" {
+		return errors.New("readiness log pattern requires LogFile or Stdout")
+	}
+	return nil
 }
 
 //ProcessStartResponse represents a start response
 type ProcessStartResponse struct {
 	Command string
+	Pid     int //started process pid
 	Info    []*ProcessInfo
 }
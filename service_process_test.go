@@ -4,8 +4,15 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestProcessService_Status(t *testing.T) {
@@ -120,3 +127,192 @@ func TestProcessService_Status(t *testing.T) {
 
 	}
 }
+
+func TestProcessService_Start(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	execService, err := GetReplayService("test/process/start/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	service, err := context.Service(endly.ProcessServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Run(context, &endly.ProcessStartRequest{
+		Target:          target,
+		Command:         "myapp",
+		Arguments:       []string{"-port", "8080"},
+		Directory:       "/opt/app",
+		ImmuneToHangups: true,
+		Stdout:          "myapp.log",
+		Readiness: &endly.ProcessReadiness{
+			Port:      listener.Addr().(*net.TCPAddr).Port,
+			TimeoutMs: 2000,
+		},
+	})
+	if !assert.Equal(t, "", response.Error) {
+		return
+	}
+	startResponse, ok := response.Response.(*endly.ProcessStartResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, 4321, startResponse.Pid)
+		if assert.Equal(t, 1, len(startResponse.Info)) {
+			assert.Equal(t, []string{"-port", "8080"}, startResponse.Info[0].Arguments)
+		}
+	}
+}
+
+func TestProcessService_StartReadiness(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	var hits, unavailable int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= atomic.LoadInt32(&unavailable) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	memStorage := storage.NewMemoryService()
+	var useCases = []struct {
+		description string
+		log         string
+		unavailable int32
+		timeoutMs   int
+		expectError string
+	}{
+		{
+			description: "ready after url responds and log pattern is matched",
+			log:         "Started myapp on port 8080\n",
+			unavailable: 1,
+			timeoutMs:   2000,
+		},
+		{
+			description: "log pattern not matched",
+			log:         "starting myapp\n",
+			timeoutMs:   50,
+			expectError: "Started myapp was not matched in scp://127.0.0.1:22/opt/app/myapp.log",
+		},
+	}
+	for _, useCase := range useCases {
+		atomic.StoreInt32(&hits, 0)
+		atomic.StoreInt32(&unavailable, useCase.unavailable)
+		memStorage.Upload("scp://127.0.0.1:22/opt/app/myapp.log", strings.NewReader(useCase.log))
+		execService, err := GetReplayService("test/process/readiness/linux")
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		context, err := OpenTestContext(manager, target, execService)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		context.State().Put(endly.UseMemoryService, true)
+		service, err := context.Service(endly.ProcessServiceID)
+		if !assert.Nil(t, err, useCase.description) {
+			context.Close()
+			continue
+		}
+		response := service.Run(context, &endly.ProcessStartRequest{
+			Target:          target,
+			Command:         "myapp",
+			Arguments:       []string{"-port", "8080"},
+			Directory:       "/opt/app",
+			ImmuneToHangups: true,
+			Stdout:          "myapp.log",
+			Readiness: &endly.ProcessReadiness{
+				URL:         server.URL + "/health",
+				LogPattern:  "Started myapp",
+				TimeoutMs:   useCase.timeoutMs,
+				FrequencyMs: 100,
+			},
+		})
+		context.Close()
+		if useCase.expectError != "" {
+			assert.Contains(t, response.Error, useCase.expectError, useCase.description)
+			continue
+		}
+		if !assert.Equal(t, "", response.Error, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, 2, atomic.LoadInt32(&hits), useCase.description)
+		startResponse, ok := response.Response.(*endly.ProcessStartResponse)
+		if assert.True(t, ok, useCase.description) {
+			assert.Equal(t, 4321, startResponse.Pid, useCase.description)
+		}
+	}
+}
+
+func TestProcessService_StartWatch(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	var target = url.NewResource("scp://127.0.0.1:22/", credentialFile)
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/process/watch/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	//watch opens its own terminal session, replayed from the recorder directory
+	recorder, err := endly.NewRecorder(endly.RecorderModeReplay, "test/process/watch/record")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context.SetRecorder(recorder)
+	service, err := context.Service(endly.ProcessServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Run(context, &endly.ProcessStartRequest{
+		Target:          target,
+		Command:         "myapp",
+		Arguments:       []string{"-port", "8080"},
+		Directory:       "/opt/app",
+		ImmuneToHangups: true,
+		Stdout:          "myapp.log",
+		Watch: &endly.ProcessWatch{
+			FrequencyMs: 10,
+			MaxRestarts: 1,
+		},
+	})
+	if !assert.Equal(t, "", response.Error) {
+		return
+	}
+	var restartEvent = func() *endly.ProcessRestartEventType {
+		for _, event := range context.Events.Events {
+			if restart, ok := event.Value["value"].(*endly.ProcessRestartEventType); ok {
+				return restart
+			}
+		}
+		return nil
+	}
+	var deadline = time.Now().Add(2 * time.Second)
+	for restartEvent() == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	event := restartEvent()
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "myapp", event.Command)
+		assert.Equal(t, 4321, event.Pid)
+		assert.Equal(t, 4322, event.NewPid)
+		assert.Equal(t, 1, event.Restarts)
+		assert.Equal(t, "", event.Error)
+	}
+}
@@ -22,7 +22,7 @@ func (s *restService) Run(context *Context, request interface{}) *ServiceRespons
 	var err error
 	switch actualReuest := request.(type) {
 	case *RestSendRequest:
-		response.Response, err = s.sendRequest(actualReuest)
+		response.Response, err = s.sendRequest(context, actualReuest)
 		if err != nil {
 			response.Error = fmt.Sprintf("failed to send %v %v", actualReuest.URL, err)
 		}
@@ -36,9 +36,14 @@ func (s *restService) Run(context *Context, request interface{}) *ServiceRespons
 	return response
 }
 
-func (s *restService) sendRequest(request *RestSendRequest) (*RestSendResponse, error) {
+func (s *restService) sendRequest(context *Context, request *RestSendRequest) (*RestSendResponse, error) {
 	var resetResponse = make(map[string]interface{})
-	err := toolbox.RouteToService(request.Method, request.URL, request.Request, &resetResponse)
+	var err error
+	if recorder := context.Recorder(); recorder != nil {
+		err = recorder.RouteToService(request.Method, request.URL, request.Request, &resetResponse)
+	} else {
+		err = toolbox.RouteToService(request.Method, request.URL, request.Request, &resetResponse)
+	}
 	if err != nil {
 		return nil, err
 	}
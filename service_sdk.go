@@ -18,8 +18,10 @@ const (
 
 type systemSdkService struct {
 	*AbstractService
-	jdkService *systemJdkService
-	goService  *systemGoService
+	jdkService    *systemJdkService
+	goService     *systemGoService
+	nodeService   *systemNodeService
+	pythonService *systemPythonService
 }
 
 func (s *systemSdkService) updateSessionSdk(context *Context, target *url.Resource, sdkInfo *SystemSdkInfo) error {
@@ -145,6 +147,10 @@ func (s *systemSdkService) setSdk(context *Context, request *SystemSdkSetRequest
 		response.SdkInfo, err = s.jdkService.setSdk(context, request)
 	case "go":
 		response.SdkInfo, err = s.goService.setSdk(context, request)
+	case "node":
+		response.SdkInfo, err = s.nodeService.setSdk(context, request)
+	case "python":
+		response.SdkInfo, err = s.pythonService.setSdk(context, request)
 
 	default:
 		return nil, fmt.Errorf("unsupported jdk: %v", request.Sdk)
@@ -157,8 +163,10 @@ func (s *systemSdkService) setSdk(context *Context, request *SystemSdkSetRequest
 //NewSystemJdkService creates a new system jdk service.
 func NewSystemJdkService() Service {
 	var result = &systemSdkService{
-		jdkService: &systemJdkService{},
-		goService:  &systemGoService{},
+		jdkService:    &systemJdkService{},
+		goService:     &systemGoService{},
+		nodeService:   &systemNodeService{},
+		pythonService: &systemPythonService{},
 		AbstractService: NewAbstractService(SdkServiceID,
 			SdkServiceSetAction),
 	}
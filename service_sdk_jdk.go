@@ -45,6 +45,7 @@ func (s *systemJdkService) checkJavaVersion(context *Context, jdkCandidate strin
 			if build == request.Version {
 				result.Version = build
 				result.Home = strings.Replace(javaHome, "/jre", "", 1)
+				result.Env = map[string]string{"JAVA_HOME": result.Home}
 				context.Execute(request.Target, fmt.Sprintf("export JAVA_HOME='%v'", result.Home))
 
 				return result, nil
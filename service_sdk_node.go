@@ -0,0 +1,132 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"strings"
+)
+
+//SdkNodeHome represents node home used when node is not managed by nvm, it matches node deployment meta target directory
+var SdkNodeHome = "/opt/sdk/node"
+
+type systemNodeService struct{}
+
+//nvmVersion returns version accepted by nvm, ranges are reduced to their lowest version
+func nvmVersion(version string) string {
+	if !isVersionRange(version) {
+		return version
+	}
+	return versionExpr.FindString(version)
+}
+
+//nvmHome returns home of the requested node version managed by nvm, version is installed by nvm if needed, empty home is returned if nvm is not available
+func (s *systemNodeService) nvmHome(context *Context, target *url.Resource, request *SystemSdkSetRequest) (string, error) {
+	var nvmDirectory = "$HOME/.nvm"
+	if directory, ok := request.Env["NVM_DIR"]; ok && directory != "" {
+		nvmDirectory = directory
+	}
+	var version = nvmVersion(request.Version)
+	if version == "" {
+		version = "node"
+	}
+	var whichCommand = &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("source %v/nvm.sh && nvm which %v", nvmDirectory, version),
+				Extraction: []*DataExtraction{
+					{
+						RegExpr: "(\\S+)/bin/node",
+						Key:     "home",
+					},
+				},
+			},
+		},
+	}
+	commandResponse, err := context.Execute(target, whichCommand)
+	if err != nil {
+		return "", err
+	}
+	var stdout = commandResponse.Stdout()
+	if CheckNoSuchFileOrDirectory(stdout) || CheckCommandNotFound(stdout) {
+		return "", nil
+	}
+	if home, ok := commandResponse.Extracted["home"]; ok {
+		return home, nil
+	}
+	if !strings.Contains(stdout, "N/A") {
+		return "", nil
+	}
+	_, err = context.Execute(target, &ExtractableCommand{
+		Options: &ExecutionOptions{
+			TimeoutMs: 300000,
+		},
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("source %v/nvm.sh && nvm install %v", nvmDirectory, version),
+				Error:   []string{"not found", "Binary download failed"},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to install node %v with nvm, %v", version, err)
+	}
+	if commandResponse, err = context.Execute(target, whichCommand); err != nil {
+		return "", err
+	}
+	return commandResponse.Extracted["home"], nil
+}
+
+//checkNodeVersion adds node home bin to the session path and checks node version
+func (s *systemNodeService) checkNodeVersion(context *Context, target *url.Resource, home string, request *SystemSdkSetRequest) (*SystemSdkInfo, error) {
+	var env = map[string]string{
+		"NODE_HOME": home,
+	}
+	commandResponse, err := context.Execute(target, &ExtractableCommand{
+		Options: &ExecutionOptions{
+			SystemPaths: []string{home + "/bin"},
+			Env:         env,
+		},
+		Executions: []*Execution{
+			{
+				Command: "node -v",
+				Extraction: []*DataExtraction{
+					{
+						RegExpr: "v(\\d+\\.\\d+\\.\\d+)",
+						Key:     "version",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	version, ok := commandResponse.Extracted["version"]
+	if !ok || !MatchVersion(request.Version, version) {
+		return nil, errSdkNotFound
+	}
+	if operatingSystem := context.OperatingSystem(target.Host()); operatingSystem != nil {
+		env["PATH"] = operatingSystem.Path.EnvValue()
+	}
+	return &SystemSdkInfo{
+		Sdk:     "node",
+		Home:    home,
+		Version: version,
+		Env:     env,
+	}, nil
+}
+
+func (s *systemNodeService) setSdk(context *Context, request *SystemSdkSetRequest) (*SystemSdkInfo, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	home, err := s.nvmHome(context, target, request)
+	if err != nil {
+		return nil, err
+	}
+	if home == "" {
+		home = SdkNodeHome
+	}
+	return s.checkNodeVersion(context, target, home, request)
+}
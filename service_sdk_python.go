@@ -0,0 +1,168 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"path"
+)
+
+//SdkPythonHome represents python home used when requested version is not available on the system path, it matches python deployment meta target directory
+var SdkPythonHome = "/opt/sdk/python"
+
+type systemPythonService struct{}
+
+//interpreterCandidates returns python interpreters to check for requested version, the most specific one goes first
+func (s *systemPythonService) interpreterCandidates(version string) []string {
+	var result = make([]string, 0)
+	var fragments = parseVersion(versionExpr.FindString(version))
+	if len(fragments) >= 2 {
+		result = append(result, fmt.Sprintf("python%v.%v", fragments[0], fragments[1]))
+	}
+	if len(fragments) >= 1 {
+		result = append(result, fmt.Sprintf("python%v", fragments[0]))
+	}
+	return append(result, "python3", "python")
+}
+
+//interpreter returns the first python interpreter matching requested version with its version
+func (s *systemPythonService) interpreter(context *Context, target *url.Resource, request *SystemSdkSetRequest) (string, string, error) {
+	for _, candidate := range s.interpreterCandidates(request.Version) {
+		commandResponse, err := context.Execute(target, &ExtractableCommand{
+			Options: &ExecutionOptions{
+				SystemPaths: []string{SdkPythonHome + "/bin"},
+			},
+			Executions: []*Execution{
+				{
+					Command: candidate + " -V 2>&1",
+					Extraction: []*DataExtraction{
+						{
+							RegExpr: "Python (\\d+\\.\\d+\\.\\d+)",
+							Key:     "version",
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return "", "", err
+		}
+		if version, ok := commandResponse.Extracted["version"]; ok && MatchVersion(request.Version, version) {
+			return candidate, version, nil
+		}
+	}
+	return "", "", errSdkNotFound
+}
+
+//createVirtualEnv creates virtualenv in the workspace unless it already exists, it returns virtualenv directory
+func (s *systemPythonService) createVirtualEnv(context *Context, target *url.Resource, interpreter, version, workspace string) (string, error) {
+	var virtualEnv = path.Join(workspace, "venv")
+	var createCommand = fmt.Sprintf("%v -m venv %v", interpreter, virtualEnv)
+	if MatchVersion("2", version) {
+		createCommand = fmt.Sprintf("virtualenv -p %v %v", interpreter, virtualEnv)
+	}
+	_, err := context.Execute(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("mkdir -p %v", workspace),
+			},
+			{
+				Command: fmt.Sprintf("ls %v/bin/activate", virtualEnv),
+			},
+			{
+				MatchOutput: "No such file or directory",
+				Command:     createCommand,
+				Error:       []string{commandNotFound, "Error"},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create virtualenv %v, %v", virtualEnv, err)
+	}
+	return virtualEnv, nil
+}
+
+//installRequirements uploads requirements into the workspace and installs them with pip
+func (s *systemPythonService) installRequirements(context *Context, target *url.Resource, workspace string, requirements *url.Resource) error {
+	var requirementsFile = path.Join(workspace, "requirements.txt")
+	_, err := context.Transfer(&Transfer{
+		Source: requirements,
+		Target: url.NewResource(fmt.Sprintf("%v://%v%v", target.ParsedURL.Scheme, target.ParsedURL.Host, requirementsFile), target.Credential),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload requirements %v, %v", requirements.URL, err)
+	}
+	_, err = context.Execute(target, &ExtractableCommand{
+		Options: &ExecutionOptions{
+			Directory: workspace,
+			TimeoutMs: 600000,
+		},
+		Executions: []*Execution{
+			{
+				Command: "pip install -r requirements.txt",
+				Error:   []string{"No matching distribution", "Could not find a version", "ERROR:"},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to install requirements %v, %v", requirements.URL, err)
+	}
+	return nil
+}
+
+func (s *systemPythonService) setSdk(context *Context, request *SystemSdkSetRequest) (*SystemSdkInfo, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	interpreter, version, err := s.interpreter(context, target, request)
+	if err != nil {
+		return nil, err
+	}
+	var env = make(map[string]string)
+	var options = &ExecutionOptions{Env: env}
+	var workspace = context.Expand(request.Workspace)
+	if workspace != "" {
+		virtualEnv, err := s.createVirtualEnv(context, target, interpreter, version, workspace)
+		if err != nil {
+			return nil, err
+		}
+		env["VIRTUAL_ENV"] = virtualEnv
+		options.SystemPaths = []string{virtualEnv + "/bin"}
+		interpreter = "python"
+	}
+	commandResponse, err := context.Execute(target, &ExtractableCommand{
+		Options: options,
+		Executions: []*Execution{
+			{
+				Command: interpreter + " -c 'import sys; print(sys.prefix)'",
+				Extraction: []*DataExtraction{
+					{
+						RegExpr: "^(/\\S*)",
+						Key:     "home",
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if workspace != "" && request.Requirements != nil {
+		requirements, err := context.ExpandResource(request.Requirements)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.installRequirements(context, target, workspace, requirements); err != nil {
+			return nil, err
+		}
+	}
+	if operatingSystem := context.OperatingSystem(target.Host()); operatingSystem != nil {
+		env["PATH"] = operatingSystem.Path.EnvValue()
+	}
+	return &SystemSdkInfo{
+		Sdk:     "python",
+		Home:    commandResponse.Extracted["home"],
+		Version: version,
+		Env:     env,
+	}, nil
+}
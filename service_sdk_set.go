@@ -4,10 +4,12 @@ import "github.com/viant/toolbox/url"
 
 //SystemSdkSetRequest represents sdk set request
 type SystemSdkSetRequest struct {
-	Sdk     string //request sdk jdk, go
-	Version string //requested version
-	Env     map[string]string
-	Target  *url.Resource //target host
+	Sdk          string //request sdk jdk, go, node, python
+	Version      string //requested version
+	Env          map[string]string
+	Target       *url.Resource //target host
+	Workspace    string        //python workspace directory, virtualenv is created in <workspace>/venv
+	Requirements *url.Resource //python pip requirements installed into workspace virtualenv
 }
 
 //SystemSdkSetResponse represents sdk response
@@ -17,9 +19,10 @@ type SystemSdkSetResponse struct {
 
 //SystemSdkInfo represents a system sdk
 type SystemSdkInfo struct {
-	Home      string //sdk path
-	Build     string //sdk build version
-	SessionID string //session id of target host
-	Sdk       string //requested sdk
-	Version   string //requested  sdk version
+	Home      string            //sdk path
+	Build     string            //sdk build version
+	SessionID string            //session id of target host
+	Sdk       string            //requested sdk
+	Version   string            //requested  sdk version
+	Env       map[string]string //session environment variables set by sdk
 }
@@ -3,6 +3,10 @@ package endly_test
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"strings"
 	"testing"
 )
 
@@ -28,3 +32,114 @@ func TestSdkService_Run(t *testing.T) {
 	//	assert.True(t, len(sdkSetResponse.SdkInfo.Home) > 0)
 	//}
 }
+
+func TestSdkService_Node(t *testing.T) {
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	var useCases = []struct {
+		description string
+		baseDir     string
+		home        string
+	}{
+		{
+			description: "node installed and managed by nvm",
+			baseDir:     "test/sdk/node/nvm/linux",
+			home:        "/root/.nvm/versions/node/v10.15.0",
+		},
+		{
+			description: "nvm not available, deployed node tarball home",
+			baseDir:     "test/sdk/node/fallback/linux",
+			home:        endly.SdkNodeHome,
+		},
+	}
+	for _, useCase := range useCases {
+		manager := endly.NewManager()
+		execService, err := GetReplayService(useCase.baseDir)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		context, err := OpenTestContext(manager, target, execService)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		service, err := context.Service(endly.SdkServiceID)
+		if !assert.Nil(t, err, useCase.description) {
+			context.Close()
+			continue
+		}
+		serviceResponse := service.Run(context, &endly.SystemSdkSetRequest{
+			Target:  target,
+			Sdk:     "node",
+			Version: "10",
+		})
+		if assert.Equal(t, "", serviceResponse.Error, useCase.description) {
+			response, ok := serviceResponse.Response.(*endly.SystemSdkSetResponse)
+			if assert.True(t, ok, useCase.description) {
+				assert.Equal(t, "node", response.SdkInfo.Sdk, useCase.description)
+				assert.Equal(t, useCase.home, response.SdkInfo.Home, useCase.description)
+				assert.Equal(t, "10.15.0", response.SdkInfo.Version, useCase.description)
+				assert.Equal(t, useCase.home, response.SdkInfo.Env["NODE_HOME"], useCase.description)
+				assert.True(t, strings.HasPrefix(response.SdkInfo.Env["PATH"], useCase.home+"/bin:"), useCase.description)
+			}
+		}
+		context.Close()
+	}
+}
+
+func TestSdkService_Python(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/sdk/python/requirements.txt", strings.NewReader("requests==2.21.0\n"))
+	var target = url.NewResource("scp://127.0.0.1:22/")
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/sdk/python/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.State().Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.SdkServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.SystemSdkSetRequest{
+		Target:       target,
+		Sdk:          "python",
+		Version:      "3.6",
+		Workspace:    "/tmp/app",
+		Requirements: url.NewResource("mem:///test/sdk/python/requirements.txt"),
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	response, ok := serviceResponse.Response.(*endly.SystemSdkSetResponse)
+	if assert.True(t, ok) {
+		assert.Equal(t, "python", response.SdkInfo.Sdk)
+		assert.Equal(t, "3.6.8", response.SdkInfo.Version)
+		assert.Equal(t, "/tmp/app/venv", response.SdkInfo.Home)
+		assert.Equal(t, "/tmp/app/venv", response.SdkInfo.Env["VIRTUAL_ENV"])
+		assert.True(t, strings.Contains(response.SdkInfo.Env["PATH"], "/tmp/app/venv/bin:"))
+	}
+
+	//virtualenv is created and requirements are uploaded into the workspace before pip install
+	object, err := memStorage.StorageObject("scp://127.0.0.1:22/tmp/app/requirements.txt")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			defer reader.Close()
+			content, _ := ioutil.ReadAll(reader)
+			assert.Equal(t, "requests==2.21.0\n", string(content))
+		}
+	}
+	var executed = make([]string, 0)
+	for _, event := range context.Events.Events {
+		if executionEvent, ok := event.Value["value"].(*endly.ExecutionStartEvent); ok {
+			executed = append(executed, executionEvent.Stdin)
+		}
+	}
+	var commands = strings.Join(executed, "\n")
+	assert.True(t, strings.Contains(commands, "python3.6 -m venv /tmp/app/venv\n"))
+	assert.True(t, strings.Index(commands, "python3.6 -m venv") < strings.Index(commands, "pip install -r requirements.txt"))
+}
@@ -15,6 +15,7 @@ type SystemTerminalSession struct {
 	envVariables     map[string]string
 	currentDirectory string
 	Deployed         map[string]string
+	PreviousDeployed map[string]string //previously deployed version keyed by app name
 	Sdk              map[string]*SystemSdkInfo
 	Mutex            *sync.RWMutex
 }
@@ -22,12 +23,13 @@ type SystemTerminalSession struct {
 //NewSystemTerminalSession create a new client session
 func NewSystemTerminalSession(id string, connection ssh.Service) (*SystemTerminalSession, error) {
 	return &SystemTerminalSession{
-		ID:           id,
-		Service:      connection,
-		envVariables: make(map[string]string),
-		Deployed:     make(map[string]string),
-		Sdk:          make(map[string]*SystemSdkInfo),
-		Mutex:        &sync.RWMutex{},
+		ID:               id,
+		Service:          connection,
+		envVariables:     make(map[string]string),
+		Deployed:         make(map[string]string),
+		PreviousDeployed: make(map[string]string),
+		Sdk:              make(map[string]*SystemSdkInfo),
+		Mutex:            &sync.RWMutex{},
 	}, nil
 }
 
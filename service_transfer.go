@@ -1,6 +1,8 @@
 package endly
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/storage"
@@ -9,11 +11,12 @@ import (
 	"io/ioutil"
 	url2 "net/url"
 	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
-//TODO refactor compress with https://golangcode.com/create-zip-files-in-go/
-
 //TransferServiceID represents transfer service id
 const TransferServiceID = "transfer"
 
@@ -60,13 +63,13 @@ func NewExpandedContentHandler(context *Context, replaceMap map[string]string, e
 }
 
 func getStorageService(context *Context, resource *url.Resource) (storage.Service, error) {
-	if context.state.Has(UseMemoryService) {
+	if context.state.Has(UseMemoryService) || context.Recorder().IsReplaying() {
 		return storage.NewMemoryService(), nil
 	}
 	return storage.NewServiceForURL(resource.URL, resource.Credential)
 }
 
-//IsShellCompressable returns true if resource can be compress via shell command.
+//IsShellCompressable returns true if resource can be compress via shell command, otherwise compression is done in Go.
 func IsShellCompressable(protScheme string) bool {
 	return protScheme == "scp" || protScheme == "file"
 }
@@ -83,70 +86,357 @@ func (s *transferService) getServiceAndResource(context *Context, resource *url.
 	return expendedResource, service, nil
 }
 
-func (s *transferService) getModificationHandler(context *Context, transfer *Transfer) func(reader io.ReadCloser) (io.ReadCloser, error) {
+//recordSource copies transfer source into the recorder directory
+func (s *transferService) recordSource(recorder *Recorder, index int, source *url.Resource, sourceService storage.Service) error {
+	var recordedResource = url.NewResource(recorder.TransferURL(index, source.ParsedURL.Path))
+	recordedService, err := storage.NewServiceForURL(recordedResource.URL, "")
+	if err != nil {
+		return err
+	}
+	defer recordedService.Close()
+	return storage.Copy(sourceService, source.URL, recordedService, recordedResource.URL, nil, nil)
+}
+
+//replaySource returns previously recorded transfer source
+func (s *transferService) replaySource(recorder *Recorder, index int, source *url.Resource) (*url.Resource, storage.Service, error) {
+	var recordedResource = url.NewResource(recorder.TransferURL(index, source.ParsedURL.Path))
+	recordedService, err := storage.NewServiceForURL(recordedResource.URL, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return recordedResource, recordedService, nil
+}
+
+func (s *transferService) getModificationHandler(context *Context, transfer *Transfer, sourceURL string) func(reader io.ReadCloser) (io.ReadCloser, error) {
 	var handler func(reader io.ReadCloser) (io.ReadCloser, error)
 	if transfer.Expand || len(transfer.Replace) > 0 {
 		handler = NewExpandedContentHandler(context, transfer.Replace, transfer.Expand)
 	}
-	return handler
+	if !transfer.Template {
+		return handler
+	}
+	var templateHandler = NewTemplateContentHandler(context, sourceURL)
+	if handler == nil {
+		return templateHandler
+	}
+	return func(reader io.ReadCloser) (io.ReadCloser, error) {
+		rendered, err := templateHandler(reader)
+		if err != nil {
+			return nil, err
+		}
+		return handler(rendered)
+	}
+}
+
+//hostLocks serializes transfers using the same host terminal session
+type hostLocks struct {
+	mutex *sync.Mutex
+	locks map[string]*sync.Mutex
 }
 
-func (s *transferService) run(context *Context, transfers ...*Transfer) (*TransferCopyResponse, error) {
+//lock locks supplied sorted hosts, it returns a function unlocking them
+func (l *hostLocks) lock(hosts []string) func() {
+	var locked = make([]*sync.Mutex, 0)
+	for _, host := range hosts {
+		l.mutex.Lock()
+		hostLock, ok := l.locks[host]
+		if !ok {
+			hostLock = &sync.Mutex{}
+			l.locks[host] = hostLock
+		}
+		l.mutex.Unlock()
+		hostLock.Lock()
+		locked = append(locked, hostLock)
+	}
+	return func() {
+		for i := len(locked) - 1; i >= 0; i-- {
+			locked[i].Unlock()
+		}
+	}
+}
+
+//shellHosts returns sorted unique hosts whose terminal session the transfer uses, shell compression runs on source and target host, docker:// resources are staged on the docker host
+func (s *transferService) shellHosts(context *Context, transfer *Transfer) []string {
+	var unique = make(map[string]bool)
+	for _, resource := range []*url.Resource{transfer.Source, transfer.Target} {
+		expanded, err := context.ExpandResource(resource)
+		if err != nil {
+			continue
+		}
+		if IsDockerResource(expanded) {
+			if container, err := newDockerResource(expanded); err == nil {
+				unique[container.Host.Host()] = true
+			}
+			continue
+		}
+		if transfer.Compress && !transfer.Sync && IsShellCompressable(expanded.ParsedURL.Scheme) {
+			unique[expanded.Host()] = true
+		}
+	}
+	var result = toolbox.MapKeysToStringSlice(unique)
+	sort.Strings(result)
+	return result
+}
+
+func (s *transferService) run(context *Context, concurrency int, transfers ...*Transfer) (*TransferCopyResponse, error) {
 	var result = &TransferCopyResponse{
 		Transferred: make([]*TransferLog, 0),
 	}
+	var recorder = context.Recorder()
+	var recordingIndexes = make([]int, len(transfers))
+	if recorder != nil {
+		for i := range transfers {
+			recordingIndexes[i] = recorder.NextTransferIndex()
+		}
+	}
+	if concurrency <= 1 {
+		for i, transfer := range transfers {
+			info, err := s.transfer(context, i, recordingIndexes[i], transfer)
+			if info != nil {
+				result.Transferred = append(result.Transferred, info)
+			}
+			if err != nil {
+				return result, err
+			}
+		}
+		return result, nil
+	}
+	//cloned contexts share terminal sessions, so transfers running commands on the same host are serialized
+	var locks = &hostLocks{mutex: &sync.Mutex{}, locks: make(map[string]*sync.Mutex)}
+	var mutex = &sync.Mutex{}
+	var group = &sync.WaitGroup{}
+	var limiter = make(chan bool, concurrency)
+	var errors = make([]string, 0)
+	for i, transfer := range transfers {
+		group.Add(1)
+		limiter <- true
+		go func(transferContext *Context, i int, transfer *Transfer) {
+			defer func() {
+				<-limiter
+				group.Done()
+			}()
+			unlock := locks.lock(s.shellHosts(transferContext, transfer))
+			defer unlock()
+			info, err := s.transfer(transferContext, i, recordingIndexes[i], transfer)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if info != nil {
+				result.Transferred = append(result.Transferred, info)
+			}
+			if err != nil {
+				errors = append(errors, err.Error())
+			}
+		}(context.Clone(), i, transfer)
+	}
+	group.Wait()
+	if len(errors) > 0 {
+		return result, fmt.Errorf("%v", strings.Join(errors, ", "))
+	}
+	return result, nil
+}
+
+func (s *transferService) transfer(context *Context, index, recordingIndex int, transfer *Transfer) (*TransferLog, error) {
+	var recorder = context.Recorder()
+	var stagingName = fmt.Sprintf("transfer%03d", index)
+	transferSource, sourceContainer, err := stageContainerSource(context, transfer.Source, stagingName)
+	if err != nil {
+		return nil, err
+	}
+	transferTarget, targetContainer, err := stageContainerTarget(context, transfer.Target, stagingName)
+	if err != nil {
+		return nil, err
+	}
+	sourceResource, sourceService, err := s.getServiceAndResource(context, transferSource)
+	if err == nil && recorder.IsReplaying() {
+		sourceResource, sourceService, err = s.replaySource(recorder, recordingIndex, sourceResource)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer sourceService.Close()
+	targetResource, targetService, err := s.getServiceAndResource(context, transferTarget)
+	if err != nil {
+		return nil, err
+	}
+	defer targetService.Close()
 
-	for _, transfer := range transfers {
+	var handler = s.getModificationHandler(context, transfer, sourceResource.URL)
+	if has, _ := sourceService.Exists(sourceResource.URL); !has {
+		return nil, fmt.Errorf("failed to copy: %v %v - Source does not exists", sourceResource.URL, targetResource.URL)
+	}
+
+	if recorder.IsRecording() {
+		if err = s.recordSource(recorder, recordingIndex, sourceResource, sourceService); err != nil {
+			return nil, fmt.Errorf("failed to record: %v, %v", sourceResource.URL, err)
+		}
+	}
 
-		sourceResource, sourceService, err := s.getServiceAndResource(context, transfer.Source)
+	//shell compression avoids sending uncompressed content over the wire, other schemes use tar.gz stream in Go
+	compressed := transfer.Compress && !transfer.Sync && !recorder.IsReplaying() && IsShellCompressable(sourceResource.ParsedURL.Scheme) && IsShellCompressable(targetResource.ParsedURL.Scheme)
+	var copyEventType = &CopyEventType{
+		SourceURL: sourceResource.URL,
+		TargetURL: targetResource.URL,
+		Expand:    transfer.Expand || len(transfer.Replace) > 0,
+	}
+	startEvent := s.Begin(context, copyEventType, Pairs("value", copyEventType), Info)
+	object, err := sourceService.StorageObject(sourceResource.URL)
+	if err != nil {
+		return nil, err
+	}
+	var progress = newTransferProgress(context, sourceResource.URL, targetResource.URL)
+	var changes []*TransferChange
+	if compressed {
+		err = s.compressSource(context, sourceResource, targetResource, object)
 		if err != nil {
 			return nil, err
 		}
-		defer sourceService.Close()
-		targetResource, targetService, err := s.getServiceAndResource(context, transfer.Target)
+		err = s.copyWithRetries(transfer, progress, func() error {
+			return storage.Copy(sourceService, sourceResource.URL, targetService, targetResource.URL, progress.handler(handler), nil)
+		})
+	} else if transfer.Compress && !transfer.Sync {
+		err = s.copyWithRetries(transfer, progress, func() error {
+			return s.copyArchive(sourceService, sourceResource.URL, targetService, targetResource.URL, func(URL string) func(reader io.ReadCloser) (io.ReadCloser, error) {
+				return s.getModificationHandler(context, transfer, URL)
+			}, progress)
+		})
+	} else if transfer.Sync {
+		changes, err = s.sync(context, transfer, sourceService, sourceResource.URL, targetService, targetResource.URL, progress)
+	} else {
+		err = s.copy(context, transfer, sourceService, sourceResource.URL, targetService, targetResource.URL, progress)
+	}
+	s.End(context)(startEvent, Pairs())
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		err = s.decompressTarget(context, sourceResource, targetResource, object)
 		if err != nil {
 			return nil, err
 		}
-		defer targetService.Close()
-
-		var handler = s.getModificationHandler(context, transfer)
-		if has, _ := sourceService.Exists(sourceResource.URL); !has {
-			return nil, fmt.Errorf("failed to copy: %v %v - Source does not exists", sourceResource.URL, targetResource.URL)
+	}
+	var sourceURL, targetURL = sourceResource.URL, targetResource.URL
+	if sourceContainer != nil {
+		sourceURL = context.Expand(transfer.Source.URL)
+		_ = sourceContainer.removeStaging(context, transferSource)
+	}
+	if targetContainer != nil && transfer.DryRun {
+		targetURL = context.Expand(transfer.Target.URL)
+		_ = targetContainer.removeStaging(context, transferTarget)
+	} else if targetContainer != nil {
+		targetURL = context.Expand(transfer.Target.URL)
+		err = targetContainer.upload(context, transferTarget)
+		_ = targetContainer.removeStaging(context, transferTarget)
+		if err != nil {
+			return nil, err
 		}
+	}
+	info := NewTransferLog(context, sourceURL, targetURL, err, transfer.Expand)
+	info.Bytes = progress.bytes
+	info.TimeTakenMs = progress.timeTakenMs()
+	info.Skipped = progress.isSkipped()
+	info.Changes = changes
+	return info, nil
+}
 
-		//TODO add in memory compression for other protocols
-		compressed := transfer.Compress && IsShellCompressable(sourceResource.ParsedURL.Scheme) && IsShellCompressable(targetResource.ParsedURL.Scheme)
-		var copyEventType = &CopyEventType{
-			SourceURL: sourceResource.URL,
-			TargetURL: targetResource.URL,
-			Expand:    transfer.Expand || len(transfer.Replace) > 0,
-		}
-		startEvent := s.Begin(context, copyEventType, Pairs("value", copyEventType), Info)
-		object, err := sourceService.StorageObject(sourceResource.URL)
+//copy copies source into target, with skip unchanged or template option folders are copied file by file
+func (s *transferService) copy(context *Context, transfer *Transfer, sourceService storage.Service, sourceURL string, targetService storage.Service, targetURL string, progress *transferProgress) error {
+	var handler = s.getModificationHandler(context, transfer, sourceURL)
+	if !transfer.SkipUnchanged && !transfer.Template {
+		return s.copyWithRetries(transfer, progress, func() error {
+			return storage.Copy(sourceService, sourceURL, targetService, targetURL, progress.handler(handler), nil)
+		})
+	}
+	object, err := sourceService.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	if object.IsFolder() {
+		objects, err := sourceService.List(sourceURL)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if compressed {
-			err = s.compressSource(context, sourceResource, targetResource, object)
-			if err != nil {
-				return nil, err
+		for _, candidate := range objects {
+			if strings.TrimRight(candidate.URL(), "/") == strings.TrimRight(sourceURL, "/") {
+				continue
+			}
+			_, name := toolbox.URLSplit(candidate.URL())
+			if err = s.copy(context, transfer, sourceService, candidate.URL(), targetService, toolbox.URLPathJoin(targetURL, name), progress); err != nil {
+				return err
 			}
 		}
-		err = storage.Copy(sourceService, sourceResource.URL, targetService, targetResource.URL, handler, nil)
-		s.End(context)(startEvent, Pairs())
+		return nil
+	}
+	if transfer.SkipUnchanged {
+		unchanged, err := s.isUnchanged(transfer, sourceService, object, targetService, targetURL, handler)
 		if err != nil {
-			return result, err
+			return err
 		}
-		if compressed {
-			err = s.decompressTarget(context, sourceResource, targetResource, object)
-			if err != nil {
-				return nil, err
-			}
+		if unchanged {
+			progress.skip()
+			return nil
 		}
-		info := NewTransferLog(context, sourceResource.URL, targetResource.URL, err, transfer.Expand)
-		result.Transferred = append(result.Transferred, info)
 	}
-	return result, nil
+	return s.copyWithRetries(transfer, progress, func() error {
+		return storage.Copy(sourceService, sourceURL, targetService, targetURL, progress.handler(handler), nil)
+	})
+}
+
+//copyWithRetries runs copy up to transfer.Retries + 1 times, progress is restored before each retry, so only the successful attempt bytes are counted
+func (s *transferService) copyWithRetries(transfer *Transfer, progress *transferProgress, copy func() error) error {
+	var err error
+	bytes, copied := progress.checkpoint()
+	for i := 0; i <= transfer.Retries; i++ {
+		if i > 0 {
+			progress.restore(bytes, copied)
+			time.Sleep(time.Duration(i*500) * time.Millisecond)
+		}
+		if err = copy(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+//isUnchanged returns true if target file matches source file, content is compared with md5 checksum if checksum flag is set, content is modified by handler or file info is not available
+func (s *transferService) isUnchanged(transfer *Transfer, sourceService storage.Service, sourceObject storage.Object, targetService storage.Service, targetURL string, handler func(reader io.ReadCloser) (io.ReadCloser, error)) (bool, error) {
+	if exists, err := targetService.Exists(targetURL); err != nil || !exists {
+		return false, err
+	}
+	targetObject, err := targetService.StorageObject(targetURL)
+	if err != nil || targetObject.IsFolder() {
+		return false, err
+	}
+	sourceInfo, targetInfo := sourceObject.FileInfo(), targetObject.FileInfo()
+	if !transfer.Checksum && handler == nil && sourceInfo != nil && targetInfo != nil {
+		return sourceInfo.Size() == targetInfo.Size() && !targetInfo.ModTime().Before(sourceInfo.ModTime()), nil
+	}
+	sourceChecksum, err := storageObjectChecksum(sourceService, sourceObject, handler)
+	if err != nil {
+		return false, err
+	}
+	targetChecksum, err := storageObjectChecksum(targetService, targetObject, nil)
+	if err != nil {
+		return false, err
+	}
+	return sourceChecksum == targetChecksum, nil
+}
+
+func storageObjectChecksum(service storage.Service, object storage.Object, handler func(reader io.ReadCloser) (io.ReadCloser, error)) (string, error) {
+	reader, err := service.Download(object)
+	if err != nil {
+		return "", err
+	}
+	if handler != nil {
+		if reader, err = handler(reader); err != nil {
+			return "", err
+		}
+	}
+	defer reader.Close()
+	var hash = md5.New()
+	if _, err = io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func (s *transferService) compressSource(context *Context, source, target *url.Resource, sourceObject storage.Object) error {
@@ -232,7 +522,7 @@ func (s *transferService) Run(context *Context, request interface{}) *ServiceRes
 	var err error
 	switch actualRequest := request.(type) {
 	case *TransferCopyRequest:
-		response.Response, err = s.run(context, actualRequest.Transfers...)
+		response.Response, err = s.run(context, actualRequest.Concurrency, actualRequest.Transfers...)
 		if err != nil {
 			response.Error = fmt.Sprintf("unable transfer, %v", err)
 		}
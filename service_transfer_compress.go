@@ -0,0 +1,135 @@
+package endly
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"time"
+)
+
+//archiveStorage writes source file or directory as tar.gz archive into writer, each file content is modified by optional handler returned by the provider
+func archiveStorage(service storage.Service, sourceURL string, writer io.Writer, handlerProvider func(URL string) func(reader io.ReadCloser) (io.ReadCloser, error)) error {
+	object, err := service.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	var files = make(map[string]storage.Object)
+	if object.IsFolder() {
+		if files, err = listStorageFiles(service, sourceURL); err != nil {
+			return err
+		}
+	} else {
+		_, name := toolbox.URLSplit(sourceURL)
+		files[name] = object
+	}
+	gzipWriter := gzip.NewWriter(writer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	var names = toolbox.MapKeysToStringSlice(files)
+	sort.Strings(names)
+	for _, name := range names {
+		if err = archiveStorageObject(service, files[name], name, tarWriter, handlerProvider(files[name].URL())); err != nil {
+			return err
+		}
+	}
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func archiveStorageObject(service storage.Service, object storage.Object, name string, writer *tar.Writer, handler func(reader io.ReadCloser) (io.ReadCloser, error)) error {
+	reader, err := service.Download(object)
+	if err != nil {
+		return err
+	}
+	var header = &tar.Header{
+		Name:     name,
+		Mode:     0644,
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}
+	var source io.Reader = reader
+	var info = object.FileInfo()
+	if info != nil {
+		header.ModTime = info.ModTime()
+		header.Size = info.Size()
+	}
+	if handler != nil || info == nil {
+		if handler != nil {
+			if reader, err = handler(reader); err != nil {
+				return err
+			}
+		}
+		//modified content or content without file info length is only known once read
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			_ = reader.Close()
+			return err
+		}
+		header.Size = int64(len(content))
+		source = bytes.NewReader(content)
+	}
+	defer reader.Close()
+	if err = writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(writer, source, header.Size)
+	return err
+}
+
+//extractStorageArchive extracts tar.gz archive from reader into target, single file archive is written to the target URL
+func extractStorageArchive(reader io.Reader, service storage.Service, targetURL string, isFolder bool) error {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		var entryURL = targetURL
+		if isFolder {
+			entryURL = toolbox.URLPathJoin(targetURL, path.Clean(header.Name))
+		}
+		if err = service.Upload(entryURL, tarReader); err != nil {
+			return fmt.Errorf("failed to extract %v, %v", header.Name, err)
+		}
+	}
+}
+
+//copyArchive streams source as tar.gz archive through progress tracker, progress counts archive bytes.
+//The archive is extracted in Go while streaming for any target scheme, thus compression only repackages content and does not reduce bytes sent to the target storage.
+func (s *transferService) copyArchive(sourceService storage.Service, sourceURL string, targetService storage.Service, targetURL string, handlerProvider func(URL string) func(reader io.ReadCloser) (io.ReadCloser, error), progress *transferProgress) error {
+	object, err := sourceService.StorageObject(sourceURL)
+	if err != nil {
+		return err
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		_ = pipeWriter.CloseWithError(archiveStorage(sourceService, sourceURL, pipeWriter, handlerProvider))
+	}()
+	reader, err := progress.handler(nil)(pipeReader)
+	if err != nil {
+		return err
+	}
+	err = extractStorageArchive(reader, targetService, targetURL, object.IsFolder())
+	_ = pipeReader.CloseWithError(err)
+	return err
+}
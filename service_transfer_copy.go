@@ -8,7 +8,8 @@ import (
 
 //TransferCopyRequest represents a resources copy request
 type TransferCopyRequest struct {
-	Transfers []*Transfer // transfers
+	Transfers   []*Transfer // transfers
+	Concurrency int         //max number of transfers running concurrently, transfers run sequentially if not greater than 1
 }
 
 //TransferCopyResponse represents a resources copy response
@@ -18,11 +19,21 @@ type TransferCopyResponse struct {
 
 //Transfer represents copy instruction
 type Transfer struct {
-	Source   *url.Resource     //source URL with credential
-	Target   *url.Resource     //target URL with credential
-	Expand   bool              //flag to substitute content with state keys
-	Compress bool              //flag to compress asset before sending over wirte and to decompress (this option is only supported on scp or file proto)
-	Replace  map[string]string //replacements map, if key if found in the conent it wil be replaced with corresponding value.
+	Source         *url.Resource     //source URL with credential
+	Target         *url.Resource     //target URL with credential
+	Expand         bool              //flag to substitute content with state keys
+	Compress       bool              //flag to compress asset before sending over wirte and to decompress, scp or file source and target use shell tar, otherwise tar.gz is streamed and extracted in Go, so content is repackaged only
+	Replace        map[string]string //replacements map, if key if found in the conent it wil be replaced with corresponding value.
+	SkipUnchanged  bool              //flag to skip files that have not changed since last copy, size and modification time are compared unless checksum is set (not applied when compressing)
+	Checksum       bool              //flag to compare md5 checksum instead of size and modification time
+	Retries        int               //number of copy retries of a failed file
+	Sync           bool              //flag to mirror source directory into target, only changed files are copied
+	Include        []string          //sync only: glob patterns of files to include, all files are included if empty
+	Exclude        []string          //sync only: glob patterns of files to exclude
+	Delete         bool              //sync only: flag to delete target files missing in the source
+	DryRun         bool              //sync only: flag to only list planned changes
+	ExpandPatterns []string          //sync only: glob patterns of text files to expand or replace, all text files if empty
+	Template       bool              //flag to render content as go text/template with state data, UDFs as functions and include of template or workflow relative files, binary files are copied as is, rendering takes place before expansion
 }
 
 //TransferLog represents transfer log
@@ -32,6 +43,10 @@ type TransferLog struct {
 	Error       string
 	Substituted string
 	State       data.Map
+	Bytes       int64             //bytes moved
+	TimeTakenMs int               //time taken
+	Skipped     bool              //true if all files were unchanged
+	Changes     []*TransferChange //sync changes
 }
 
 //NewTransferLog create a new transfer log
@@ -0,0 +1,122 @@
+package endly
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+//TransferProgressEventFrequencyMs represents min time between transfer progress events
+var TransferProgressEventFrequencyMs = 1000
+
+//TransferProgressEventType represents a transfer progress event
+type TransferProgressEventType struct {
+	SourceURL     string
+	TargetURL     string
+	Bytes         int64
+	ThroughputKBs float64
+}
+
+//transferProgress tracks bytes moved and files skipped by a transfer
+type transferProgress struct {
+	context   *Context
+	sourceURL string
+	targetURL string
+	startTime time.Time
+	lastEvent time.Time
+	bytes     int64
+	copied    int
+	skipped   int
+	mutex     *sync.Mutex
+}
+
+func (p *transferProgress) add(bytes int, final bool) {
+	p.mutex.Lock()
+	p.bytes += int64(bytes)
+	var now = time.Now()
+	if !final && now.Sub(p.lastEvent) < time.Duration(TransferProgressEventFrequencyMs)*time.Millisecond {
+		p.mutex.Unlock()
+		return
+	}
+	p.lastEvent = now
+	var event = &TransferProgressEventType{
+		SourceURL: p.sourceURL,
+		TargetURL: p.targetURL,
+		Bytes:     p.bytes,
+	}
+	if elapsed := now.Sub(p.startTime).Seconds(); elapsed > 0 {
+		event.ThroughputKBs = float64(p.bytes) / 1024.0 / elapsed
+	}
+	p.mutex.Unlock()
+	AddEvent(p.context, event, Pairs("value", event), Debug)
+}
+
+//checkpoint returns bytes and copied files counted so far
+func (p *transferProgress) checkpoint() (int64, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.bytes, p.copied
+}
+
+//restore reverts counters to supplied checkpoint, so that bytes of a failed attempt are not counted again by a retry
+func (p *transferProgress) restore(bytes int64, copied int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.bytes = bytes
+	p.copied = copied
+}
+
+func (p *transferProgress) skip() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.skipped++
+}
+
+//isSkipped returns true if all files were unchanged
+func (p *transferProgress) isSkipped() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.copied == 0 && p.skipped > 0
+}
+
+//timeTakenMs returns time elapsed since transfer started
+func (p *transferProgress) timeTakenMs() int {
+	return int(time.Since(p.startTime) / time.Millisecond)
+}
+
+//handler returns content handler counting bytes read by the target storage, it wraps optional modification handler
+func (p *transferProgress) handler(handler func(reader io.ReadCloser) (io.ReadCloser, error)) func(reader io.ReadCloser) (io.ReadCloser, error) {
+	return func(reader io.ReadCloser) (io.ReadCloser, error) {
+		if handler != nil {
+			var err error
+			if reader, err = handler(reader); err != nil {
+				return nil, err
+			}
+		}
+		p.mutex.Lock()
+		p.copied++
+		p.mutex.Unlock()
+		return &progressReader{ReadCloser: reader, progress: p}, nil
+	}
+}
+
+type progressReader struct {
+	io.ReadCloser
+	progress *transferProgress
+}
+
+func (r *progressReader) Read(buffer []byte) (int, error) {
+	read, err := r.ReadCloser.Read(buffer)
+	r.progress.add(read, err == io.EOF)
+	return read, err
+}
+
+func newTransferProgress(context *Context, sourceURL, targetURL string) *transferProgress {
+	return &transferProgress{
+		context:   context,
+		sourceURL: sourceURL,
+		targetURL: targetURL,
+		startTime: time.Now(),
+		mutex:     &sync.Mutex{},
+	}
+}
@@ -0,0 +1,175 @@
+package endly
+
+import (
+	"bytes"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	//TransferChangeCreate represents a sync change creating a missing target file
+	TransferChangeCreate = "create"
+	//TransferChangeUpdate represents a sync change overriding a modified target file
+	TransferChangeUpdate = "update"
+	//TransferChangeDelete represents a sync change deleting an extraneous target file
+	TransferChangeDelete = "delete"
+)
+
+//TransferChange represents a sync change, in dry run mode changes are only planned
+type TransferChange struct {
+	Action    string
+	SourceURL string
+	TargetURL string
+}
+
+//matchTransferPattern returns true if relative path matches any glob pattern; patterns with / are matched against the relative path, patterns ending with / match a directory prefix, other patterns are matched against the file name
+func matchTransferPattern(patterns []string, relativePath string) bool {
+	var _, name = path.Split(relativePath)
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(relativePath, pattern) {
+				return true
+			}
+			continue
+		}
+		var candidate = name
+		if strings.Contains(pattern, "/") {
+			candidate = relativePath
+		}
+		if matched, _ := path.Match(pattern, candidate); matched {
+			return true
+		}
+	}
+	return false
+}
+
+//isSyncable returns true if relative path is included and not excluded by the transfer patterns
+func (t *Transfer) isSyncable(relativePath string) bool {
+	if len(t.Include) > 0 && !matchTransferPattern(t.Include, relativePath) {
+		return false
+	}
+	return !matchTransferPattern(t.Exclude, relativePath)
+}
+
+func isTextContent(content []byte) bool {
+	return utf8.Valid(content) && !bytes.Contains(content, []byte{0})
+}
+
+//listStorageFiles returns files under base URL keyed by relative path
+func listStorageFiles(service storage.Service, baseURL string) (map[string]storage.Object, error) {
+	var result = make(map[string]storage.Object)
+	if exists, err := service.Exists(baseURL); err != nil || !exists {
+		return result, err
+	}
+	return result, collectStorageFiles(service, baseURL, "", result)
+}
+
+func collectStorageFiles(service storage.Service, URL, relativePath string, result map[string]storage.Object) error {
+	objects, err := service.List(URL)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if strings.TrimRight(object.URL(), "/") == strings.TrimRight(URL, "/") {
+			continue
+		}
+		_, name := toolbox.URLSplit(object.URL())
+		var objectPath = path.Join(relativePath, name)
+		if object.IsFolder() {
+			if err = collectStorageFiles(service, object.URL(), objectPath, result); err != nil {
+				return err
+			}
+			continue
+		}
+		result[objectPath] = object
+	}
+	return nil
+}
+
+//syncHandler returns expansion handler for supplied relative path, expansion applies only to text files matching expand patterns
+func (s *transferService) syncHandler(context *Context, transfer *Transfer, sourceURL, relativePath string) func(reader io.ReadCloser) (io.ReadCloser, error) {
+	var handler = s.getModificationHandler(context, transfer, sourceURL)
+	if handler == nil || (len(transfer.ExpandPatterns) > 0 && !matchTransferPattern(transfer.ExpandPatterns, relativePath)) {
+		return nil
+	}
+	return func(reader io.ReadCloser) (io.ReadCloser, error) {
+		defer reader.Close()
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !isTextContent(content) {
+			return ioutil.NopCloser(bytes.NewReader(content)), nil
+		}
+		return handler(ioutil.NopCloser(bytes.NewReader(content)))
+	}
+}
+
+//sync mirrors source directory into target directory, it returns applied or, in dry run mode, planned changes
+func (s *transferService) sync(context *Context, transfer *Transfer, sourceService storage.Service, sourceURL string, targetService storage.Service, targetURL string, progress *transferProgress) ([]*TransferChange, error) {
+	var changes = make([]*TransferChange, 0)
+	sourceFiles, err := listStorageFiles(sourceService, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	targetFiles, err := listStorageFiles(targetService, targetURL)
+	if err != nil {
+		return nil, err
+	}
+	var sourcePaths = toolbox.MapKeysToStringSlice(sourceFiles)
+	sort.Strings(sourcePaths)
+	for _, relativePath := range sourcePaths {
+		if !transfer.isSyncable(relativePath) {
+			continue
+		}
+		var sourceObject = sourceFiles[relativePath]
+		var targetFileURL = toolbox.URLPathJoin(targetURL, relativePath)
+		var handler = s.syncHandler(context, transfer, sourceObject.URL(), relativePath)
+		var change = &TransferChange{Action: TransferChangeCreate, SourceURL: sourceObject.URL(), TargetURL: targetFileURL}
+		if _, has := targetFiles[relativePath]; has {
+			unchanged, err := s.isUnchanged(transfer, sourceService, sourceObject, targetService, targetFileURL, handler)
+			if err != nil {
+				return nil, err
+			}
+			if unchanged {
+				progress.skip()
+				continue
+			}
+			change.Action = TransferChangeUpdate
+		}
+		changes = append(changes, change)
+		if transfer.DryRun {
+			continue
+		}
+		if err = s.copyWithRetries(transfer, progress, func() error {
+			return storage.Copy(sourceService, sourceObject.URL(), targetService, targetFileURL, progress.handler(handler), nil)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if !transfer.Delete {
+		return changes, nil
+	}
+	var targetPaths = toolbox.MapKeysToStringSlice(targetFiles)
+	sort.Strings(targetPaths)
+	for _, relativePath := range targetPaths {
+		if _, has := sourceFiles[relativePath]; has || !transfer.isSyncable(relativePath) {
+			continue
+		}
+		var targetObject = targetFiles[relativePath]
+		changes = append(changes, &TransferChange{Action: TransferChangeDelete, TargetURL: targetObject.URL()})
+		if transfer.DryRun {
+			continue
+		}
+		if err = targetService.Delete(targetObject); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,124 @@
+package endly
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//TransferTemplateMaxIncludeDepth represents max depth of nested template includes
+var TransferTemplateMaxIncludeDepth = 10
+
+var templateFunctionNameExpr = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+//NewTemplateContentHandler returns a content handler rendering text content as go text/template, binary content is passed as is, name is used in rendering error messages and to resolve relative includes
+func NewTemplateContentHandler(context *Context, name string) func(reader io.ReadCloser) (io.ReadCloser, error) {
+	return func(reader io.ReadCloser) (io.ReadCloser, error) {
+		defer reader.Close()
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !isTextContent(content) {
+			return ioutil.NopCloser(bytes.NewReader(content)), nil
+		}
+		result, err := renderTemplate(context, name, string(content), 0)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader([]byte(result))), nil
+	}
+}
+
+//templateFunctions returns UDFs bound to the context state and include function resolving relative URLs against the including template
+func templateFunctions(context *Context, name string, depth int) template.FuncMap {
+	var state = context.State()
+	var result = template.FuncMap{
+		"include": func(URL string) (string, error) {
+			return includeTemplate(context, name, URL, depth+1)
+		},
+	}
+	for name, udf := range UdfRegistry {
+		if !templateFunctionNameExpr.MatchString(name) {
+			continue
+		}
+		var function = udf
+		result[name] = func(source interface{}) (interface{}, error) {
+			return function(source, state)
+		}
+	}
+	return result
+}
+
+//renderTemplate renders content with the context state, parsing and execution errors report template name and line
+func renderTemplate(context *Context, name, content string, depth int) (string, error) {
+	if depth > TransferTemplateMaxIncludeDepth {
+		return "", fmt.Errorf("failed to render %v, exceeded max include depth: %v", name, TransferTemplateMaxIncludeDepth)
+	}
+	aTemplate, err := template.New(name).Funcs(templateFunctions(context, name, depth)).Option("missingkey=zero").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to render %v", err)
+	}
+	var writer = new(bytes.Buffer)
+	if err = aTemplate.Execute(writer, map[string]interface{}(context.State())); err != nil {
+		return "", fmt.Errorf("failed to render %v", err)
+	}
+	return writer.String(), nil
+}
+
+//includeTemplate downloads and renders included template, relative URL is resolved against the including template directory, then against the workflow URL
+func includeTemplate(context *Context, parentURL, URL string, depth int) (string, error) {
+	URL = context.Expand(URL)
+	if !strings.Contains(URL, "://") && !strings.HasPrefix(URL, "/") {
+		if candidateURL, ok := siblingTemplateURL(context, parentURL, URL); ok {
+			URL = candidateURL
+		}
+	}
+	resource, err := context.ExpandResource(&url.Resource{URL: URL})
+	if err != nil {
+		return "", err
+	}
+	service, err := getStorageService(context, resource)
+	if err != nil {
+		return "", err
+	}
+	defer service.Close()
+	object, err := service.StorageObject(resource.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to include %v, %v", resource.URL, err)
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return "", fmt.Errorf("failed to include %v, %v", resource.URL, err)
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return renderTemplate(context, resource.URL, string(content), depth)
+}
+
+//siblingTemplateURL returns relative URL joined with the parent template directory if such template exists
+func siblingTemplateURL(context *Context, parentURL, URL string) (string, bool) {
+	if !strings.Contains(parentURL, "://") {
+		return "", false
+	}
+	parentDirectory, _ := toolbox.URLSplit(parentURL)
+	var candidate = url.NewResource(toolbox.URLPathJoin(parentDirectory, URL))
+	service, err := getStorageService(context, candidate)
+	if err != nil {
+		return "", false
+	}
+	defer service.Close()
+	if exists, err := service.Exists(candidate.URL); err != nil || !exists {
+		return "", false
+	}
+	return candidate.URL, true
+}
@@ -2,6 +2,7 @@ package endly_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
@@ -9,12 +10,60 @@ import (
 	"github.com/viant/toolbox/data"
 	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"io"
 	"io/ioutil"
+	"os"
 	"path"
 	"strings"
+	"sync/atomic"
 	"testing"
 )
 
+//faultyStorage fails the first uploads after reading their content and hides storage objects file info
+type faultyStorage struct {
+	storage.Service
+	failures int32
+}
+
+type faultyObject struct {
+	storage.Object
+}
+
+func (o *faultyObject) FileInfo() os.FileInfo {
+	return nil
+}
+
+func (s *faultyStorage) StorageObject(URL string) (storage.Object, error) {
+	object, err := s.Service.StorageObject(URL)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyObject{Object: object}, nil
+}
+
+func (s *faultyStorage) Download(object storage.Object) (io.ReadCloser, error) {
+	if faulty, ok := object.(*faultyObject); ok {
+		object = faulty.Object
+	}
+	return s.Service.Download(object)
+}
+
+func (s *faultyStorage) Upload(URL string, reader io.Reader) error {
+	if atomic.AddInt32(&s.failures, -1) >= 0 {
+		_, _ = ioutil.ReadAll(reader)
+		return errors.New("connection reset by peer")
+	}
+	return s.Service.Upload(URL, reader)
+}
+
+var faultyStorageService = &faultyStorage{Service: storage.NewMemoryService()}
+
+func init() {
+	storage.Registry().Register("faulty", func(credentialFile string) (storage.Service, error) {
+		return faultyStorageService, nil
+	})
+}
+
 func updateContext(context *endly.Context) {
 	var state = context.State()
 
@@ -56,7 +105,7 @@ func TestTransferService_Copy(t *testing.T) {
 		{
 			"",
 			&endly.TransferCopyRequest{
-				[]*endly.Transfer{
+				Transfers: []*endly.Transfer{
 					{
 						Source: url.NewResource("mem:///test/copy/transfer/"),
 						Target: url.NewResource("mem:///tmp/copy1"),
@@ -72,7 +121,7 @@ func TestTransferService_Copy(t *testing.T) {
 		{
 			"",
 			&endly.TransferCopyRequest{
-				[]*endly.Transfer{
+				Transfers: []*endly.Transfer{
 					{
 						Source: url.NewResource("mem:///test/copy/transfer/"),
 						Target: url.NewResource("mem:///tmp/copy1"),
@@ -88,7 +137,7 @@ func TestTransferService_Copy(t *testing.T) {
 		{
 			"test/transfer/copy/compress/dir/darwin",
 			&endly.TransferCopyRequest{
-				[]*endly.Transfer{
+				Transfers: []*endly.Transfer{
 					{
 						Source:   url.NewResource("scp://127.0.0.1:22/tmp/copy2_source"),
 						Target:   url.NewResource("/tmp/copy2_target"),
@@ -104,7 +153,7 @@ func TestTransferService_Copy(t *testing.T) {
 		{
 			"test/transfer/copy/compress/file1/darwin",
 			&endly.TransferCopyRequest{
-				[]*endly.Transfer{
+				Transfers: []*endly.Transfer{
 					{
 						Source:   url.NewResource("scp://127.0.0.1:22/tmp/copy2_source/config1.json"),
 						Target:   url.NewResource("/tmp/copy3_target"),
@@ -120,7 +169,7 @@ func TestTransferService_Copy(t *testing.T) {
 		{
 			"test/transfer/copy/compress/file2/darwin",
 			&endly.TransferCopyRequest{
-				[]*endly.Transfer{
+				Transfers: []*endly.Transfer{
 					{
 						Source:   url.NewResource("scp://127.0.0.1:22/tmp/copy2_source/config2.json"),
 						Target:   url.NewResource("/tmp/copy4_target/config2.json"),
@@ -181,3 +230,535 @@ func TestTransferService_Copy(t *testing.T) {
 	}
 
 }
+
+func TestIsDockerResource(t *testing.T) {
+	assert.True(t, endly.IsDockerResource(url.NewResource("docker://db1/etc/mysql/my.cnf")))
+	assert.True(t, endly.IsDockerResource(url.NewResource("docker://db1@10.0.0.2:22/var/log/")))
+	assert.False(t, endly.IsDockerResource(url.NewResource("scp://127.0.0.1:22/etc/hosts")))
+	assert.False(t, endly.IsDockerResource(nil))
+}
+
+func TestTransferService_SkipUnchanged(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/skip/source/app.properties", strings.NewReader("port=8080"))
+	memStorage.Upload("mem:///test/skip/source/lib/app.jar", strings.NewReader("binary"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	context.State().Put(endly.UseMemoryService, true)
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var request = &endly.TransferCopyRequest{
+		Concurrency: 2,
+		Transfers: []*endly.Transfer{
+			{
+				Source:        url.NewResource("mem:///test/skip/source/app.properties"),
+				Target:        url.NewResource("mem:///test/skip/target/app.properties"),
+				SkipUnchanged: true,
+				Checksum:      true,
+			},
+			{
+				Source:        url.NewResource("mem:///test/skip/source/lib/"),
+				Target:        url.NewResource("mem:///test/skip/target/lib/"),
+				SkipUnchanged: true,
+				Checksum:      true,
+			},
+		},
+	}
+	for i, expectSkipped := range []bool{false, true} {
+		serviceResponse := service.Run(context, request)
+		if !assert.Equal(t, "", serviceResponse.Error, i) {
+			return
+		}
+		response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+		if assert.True(t, ok) && assert.EqualValues(t, 2, len(response.Transferred)) {
+			for _, transferred := range response.Transferred {
+				assert.EqualValues(t, expectSkipped, transferred.Skipped, transferred.SourceURL)
+				if !expectSkipped {
+					assert.True(t, transferred.Bytes > 0, transferred.SourceURL)
+				}
+			}
+		}
+	}
+}
+
+func TestTransferService_Sync(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/sync/source/conf/app.properties", strings.NewReader("port=$port"))
+	memStorage.Upload("mem:///test/sync/source/lib/app.jar", strings.NewReader("\x00$port"))
+	memStorage.Upload("mem:///test/sync/source/build.log", strings.NewReader("log"))
+	memStorage.Upload("mem:///test/sync/target/conf/old.properties", strings.NewReader("old"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	state.Put("port", "8080")
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var transfer = &endly.Transfer{
+		Source:  url.NewResource("mem:///test/sync/source"),
+		Target:  url.NewResource("mem:///test/sync/target"),
+		Sync:    true,
+		Expand:  true,
+		Exclude: []string{"*.log"},
+		Delete:  true,
+		DryRun:  true,
+	}
+	var request = &endly.TransferCopyRequest{Transfers: []*endly.Transfer{transfer}}
+	serviceResponse := service.Run(context, request)
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	response := serviceResponse.Response.(*endly.TransferCopyResponse)
+	if assert.EqualValues(t, 3, len(response.Transferred[0].Changes)) {
+		assert.EqualValues(t, endly.TransferChangeDelete, response.Transferred[0].Changes[2].Action)
+	}
+	exists, _ := memStorage.Exists("mem:///test/sync/target/conf/app.properties")
+	assert.False(t, exists)
+
+	transfer.DryRun = false
+	serviceResponse = service.Run(context, request)
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	var expected = map[string]string{
+		"mem:///test/sync/target/conf/app.properties": "port=8080",
+		"mem:///test/sync/target/lib/app.jar":         "\x00$port",
+	}
+	for URL, expectedContent := range expected {
+		object, err := memStorage.StorageObject(URL)
+		if assert.Nil(t, err, URL) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err, URL) {
+				content, _ := ioutil.ReadAll(reader)
+				_ = reader.Close()
+				assert.EqualValues(t, expectedContent, string(content), URL)
+			}
+		}
+	}
+	for _, URL := range []string{"mem:///test/sync/target/build.log", "mem:///test/sync/target/conf/old.properties"} {
+		exists, _ = memStorage.Exists(URL)
+		assert.False(t, exists, URL)
+	}
+}
+
+func TestTransferService_CompressInGo(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/compress/source/app.properties", strings.NewReader("port=$port"))
+	memStorage.Upload("mem:///test/compress/source/lib/app.jar", strings.NewReader("binary"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	state.Put("port", "8080")
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("mem:///test/compress/source"),
+				Target:   url.NewResource("mem:///test/compress/target"),
+				Compress: true,
+				Expand:   true,
+			},
+			{
+				Source:   url.NewResource("mem:///test/compress/source/lib/app.jar"),
+				Target:   url.NewResource("mem:///test/compress/app.jar"),
+				Compress: true,
+			},
+		},
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	var expected = map[string]string{
+		"mem:///test/compress/target/app.properties": "port=8080",
+		"mem:///test/compress/target/lib/app.jar":    "binary",
+		"mem:///test/compress/app.jar":               "binary",
+	}
+	for URL, expectedContent := range expected {
+		object, err := memStorage.StorageObject(URL)
+		if assert.Nil(t, err, URL) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err, URL) {
+				content, _ := ioutil.ReadAll(reader)
+				_ = reader.Close()
+				assert.EqualValues(t, expectedContent, string(content), URL)
+			}
+		}
+	}
+}
+
+func TestTransferService_Template(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/template/source/app.properties", strings.NewReader("{{if .debug}}log=debug\n{{end}}{{range .hosts}}host={{.}}\n{{end}}{{include \"mem:///test/template/footer.txt\"}}"))
+	memStorage.Upload("mem:///test/template/footer.txt", strings.NewReader("port={{.port}}"))
+	memStorage.Upload("mem:///test/template/invalid/app.properties", strings.NewReader("line1\n{{if .debug}}"))
+	//binary files are copied as is
+	memStorage.Upload("mem:///test/template/source/logo.png", bytes.NewReader([]byte("\x89PNG\x00{{")))
+	//relative includes are resolved against the including template
+	memStorage.Upload("mem:///test/template/nested/app.properties", strings.NewReader("{{include \"partials/header.txt\"}}"))
+	memStorage.Upload("mem:///test/template/nested/partials/header.txt", strings.NewReader("name=app\n{{include \"footer.txt\"}}"))
+	memStorage.Upload("mem:///test/template/nested/partials/footer.txt", strings.NewReader("port={{.port}}"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	state.Put("debug", true)
+	state.Put("hosts", []interface{}{"h1", "h2"})
+	state.Put("port", "8080")
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("mem:///test/template/source"),
+				Target:   url.NewResource("mem:///test/template/target"),
+				Template: true,
+			},
+		},
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	object, err := memStorage.StorageObject("mem:///test/template/target/app.properties")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.EqualValues(t, "log=debug\nhost=h1\nhost=h2\nport=8080", string(content))
+		}
+	}
+	object, err = memStorage.StorageObject("mem:///test/template/target/logo.png")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.EqualValues(t, []byte("\x89PNG\x00{{"), content)
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("mem:///test/template/nested/app.properties"),
+				Target:   url.NewResource("mem:///test/template/target/nested.properties"),
+				Template: true,
+			},
+		},
+	})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		object, err = memStorage.StorageObject("mem:///test/template/target/nested.properties")
+		if assert.Nil(t, err) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err) {
+				content, _ := ioutil.ReadAll(reader)
+				_ = reader.Close()
+				assert.EqualValues(t, "name=app\nport=8080", string(content))
+			}
+		}
+	}
+
+	serviceResponse = service.Run(context, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("mem:///test/template/invalid/app.properties"),
+				Target:   url.NewResource("mem:///test/template/target/invalid.properties"),
+				Template: true,
+			},
+		},
+	})
+	assert.True(t, strings.Contains(serviceResponse.Error, "mem:///test/template/invalid/app.properties:2"), serviceResponse.Error)
+}
+
+func TestTransferService_DockerResource(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	var stagingURL = "scp://127.0.0.1:22/tmp/endly/docker/test-session/transfer000/db1/my.cnf"
+	memStorage.Upload(stagingURL, strings.NewReader("[mysqld]\nport=3306"))
+	memStorage.Upload("mem:///test/docker/resource/my.cnf", strings.NewReader("[mysqld]\nport=${dbPort}"))
+
+	manager := endly.NewManager()
+	execService, err := GetReplayService("test/docker/resource/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, url.NewResource("scp://127.0.0.1:22/"), execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	context.SessionID = "test-session"
+	context.State().Put(endly.UseMemoryService, true)
+	context.State().Put("dbPort", "3307")
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	{ //download: docker cp into staging directory, copy from staging, staging removed
+		serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+			Transfers: []*endly.Transfer{
+				{
+					Source: url.NewResource("docker://db1/etc/mysql/my.cnf"),
+					Target: url.NewResource("mem:///tmp/docker/download/my.cnf"),
+				},
+			},
+		})
+		if assert.EqualValues(t, "", serviceResponse.Error) {
+			response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+			if assert.True(t, ok) && assert.Equal(t, 1, len(response.Transferred)) {
+				assert.EqualValues(t, "docker://db1/etc/mysql/my.cnf", response.Transferred[0].SourceURL)
+			}
+		}
+		object, err := memStorage.StorageObject("mem:///tmp/docker/download/my.cnf")
+		if assert.Nil(t, err) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err) {
+				defer reader.Close()
+				content, _ := ioutil.ReadAll(reader)
+				assert.EqualValues(t, "[mysqld]\nport=3306", string(content))
+			}
+		}
+	}
+
+	{ //upload: expanded copy into staging directory, docker cp into container, staging removed
+		serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+			Transfers: []*endly.Transfer{
+				{
+					Source: url.NewResource("mem:///test/docker/resource/my.cnf"),
+					Target: url.NewResource("docker://db1/etc/mysql/my.cnf"),
+					Expand: true,
+				},
+			},
+		})
+		if assert.EqualValues(t, "", serviceResponse.Error) {
+			response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+			if assert.True(t, ok) && assert.Equal(t, 1, len(response.Transferred)) {
+				assert.EqualValues(t, "docker://db1/etc/mysql/my.cnf", response.Transferred[0].TargetURL)
+			}
+		}
+		object, err := memStorage.StorageObject(stagingURL)
+		if assert.Nil(t, err) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err) {
+				defer reader.Close()
+				content, _ := ioutil.ReadAll(reader)
+				assert.EqualValues(t, "[mysqld]\nport=3307", string(content))
+			}
+		}
+	}
+}
+
+func TestTransferService_CompressUpload(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/compress/upload/app.properties", strings.NewReader("port=$port"))
+	memStorage.Upload("mem:///test/compress/upload/lib/app.jar", strings.NewReader(strings.Repeat("binary", 1024)))
+	atomic.StoreInt32(&faultyStorageService.failures, 0)
+	//source without file info is buffered to get archive entry size
+	faultyStorageService.Upload("faulty:///test/compress/faulty/app.jar", strings.NewReader("binary"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	var state = context.State()
+	state.Put(endly.UseMemoryService, true)
+	state.Put("port", "8080")
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("mem:///test/compress/upload"),
+				Target:   url.NewResource("scp://127.0.0.1:22/tmp/compress/target"),
+				Compress: true,
+				Expand:   true,
+			},
+		},
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+	if !assert.True(t, ok) || !assert.Equal(t, 1, len(response.Transferred)) {
+		return
+	}
+	//progress counts archive bytes
+	assert.True(t, response.Transferred[0].Bytes > 0)
+	assert.True(t, response.Transferred[0].Bytes < 6*1024, "archive should be compressed")
+
+	//archive is extracted in Go, nothing is left beside the target
+	_, err = memStorage.StorageObject("scp://127.0.0.1:22/tmp/compress/target.tar.gz")
+	assert.NotNil(t, err)
+	var expected = map[string]string{
+		"scp://127.0.0.1:22/tmp/compress/target/app.properties": "port=8080",
+		"scp://127.0.0.1:22/tmp/compress/target/lib/app.jar":    strings.Repeat("binary", 1024),
+	}
+	for URL, expectedContent := range expected {
+		object, err := memStorage.StorageObject(URL)
+		if assert.Nil(t, err, URL) {
+			reader, err := memStorage.Download(object)
+			if assert.Nil(t, err, URL) {
+				content, _ := ioutil.ReadAll(reader)
+				_ = reader.Close()
+				assert.EqualValues(t, expectedContent, string(content), URL)
+			}
+		}
+	}
+
+	//faulty storage is only used without memory service override
+	faultyContext := manager.NewContext(toolbox.NewContext())
+	defer faultyContext.Close()
+	serviceResponse = service.Run(faultyContext, &endly.TransferCopyRequest{
+		Transfers: []*endly.Transfer{
+			{
+				Source:   url.NewResource("faulty:///test/compress/faulty/app.jar"),
+				Target:   url.NewResource("mem:///test/compress/faulty/target/app.jar"),
+				Compress: true,
+			},
+		},
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	object, err := memStorage.StorageObject("mem:///test/compress/faulty/target/app.jar")
+	if assert.Nil(t, err) {
+		reader, err := memStorage.Download(object)
+		if assert.Nil(t, err) {
+			content, _ := ioutil.ReadAll(reader)
+			_ = reader.Close()
+			assert.EqualValues(t, "binary", string(content))
+		}
+	}
+}
+
+func TestTransferService_Retries(t *testing.T) {
+	var content = "retried content"
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/retry/source/app.jar", strings.NewReader(content))
+	manager := endly.NewManager()
+	service, err := manager.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		failures    int32
+		expectError bool
+	}{
+		{"upload succeeds on retry", 1, false},
+		{"upload fails all attempts", 2, true},
+	}
+	for _, useCase := range useCases {
+		context := manager.NewContext(toolbox.NewContext())
+		atomic.StoreInt32(&faultyStorageService.failures, useCase.failures)
+		serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+			Transfers: []*endly.Transfer{
+				{
+					Source:  url.NewResource("mem:///test/retry/source/app.jar"),
+					Target:  url.NewResource("faulty:///test/retry/target/app.jar"),
+					Retries: 1,
+				},
+			},
+		})
+		if useCase.expectError {
+			assert.Contains(t, serviceResponse.Error, "connection reset by peer", useCase.description)
+			context.Close()
+			continue
+		}
+		if !assert.Equal(t, "", serviceResponse.Error, useCase.description) {
+			context.Close()
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+		if assert.True(t, ok, useCase.description) && assert.Equal(t, 1, len(response.Transferred), useCase.description) {
+			//only bytes of the successful attempt are counted
+			assert.EqualValues(t, len(content), response.Transferred[0].Bytes, useCase.description)
+		}
+		var progressEvent *endly.TransferProgressEventType
+		for _, event := range context.Events.Events {
+			if candidate, ok := event.Value["value"].(*endly.TransferProgressEventType); ok {
+				progressEvent = candidate
+			}
+		}
+		if assert.NotNil(t, progressEvent, useCase.description) {
+			assert.EqualValues(t, len(content), progressEvent.Bytes, useCase.description)
+			assert.Equal(t, "faulty:///test/retry/target/app.jar", progressEvent.TargetURL, useCase.description)
+		}
+		object, err := faultyStorageService.StorageObject("faulty:///test/retry/target/app.jar")
+		if assert.Nil(t, err, useCase.description) {
+			reader, err := faultyStorageService.Download(object)
+			if assert.Nil(t, err, useCase.description) {
+				uploaded, _ := ioutil.ReadAll(reader)
+				reader.Close()
+				assert.Equal(t, content, string(uploaded), useCase.description)
+			}
+		}
+		context.Close()
+	}
+}
+
+func TestTransferService_SkipUnchangedFileInfo(t *testing.T) {
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("mem:///test/unchanged/source/app.properties", strings.NewReader("port=8080"))
+	//target uploaded after source with the same size is unchanged without comparing content
+	memStorage.Upload("mem:///test/unchanged/target/same.properties", strings.NewReader("port=9090"))
+	memStorage.Upload("mem:///test/unchanged/target/resized.properties", strings.NewReader("port=80"))
+	atomic.StoreInt32(&faultyStorageService.failures, 0)
+	//target without file info falls back to checksum
+	faultyStorageService.Upload("faulty:///test/unchanged/target/checksum.properties", strings.NewReader("port=8080"))
+	faultyStorageService.Upload("faulty:///test/unchanged/target/modified.properties", strings.NewReader("port=9090"))
+
+	manager := endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.TransferServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		target  string
+		skipped bool
+	}{
+		{"mem:///test/unchanged/target/same.properties", true},
+		{"mem:///test/unchanged/target/resized.properties", false},
+		{"faulty:///test/unchanged/target/checksum.properties", true},
+		{"faulty:///test/unchanged/target/modified.properties", false},
+	}
+	for _, useCase := range useCases {
+		serviceResponse := service.Run(context, &endly.TransferCopyRequest{
+			Transfers: []*endly.Transfer{
+				{
+					Source:        url.NewResource("mem:///test/unchanged/source/app.properties"),
+					Target:        url.NewResource(useCase.target),
+					SkipUnchanged: true,
+				},
+			},
+		})
+		if !assert.Equal(t, "", serviceResponse.Error, useCase.target) {
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.TransferCopyResponse)
+		if assert.True(t, ok, useCase.target) && assert.Equal(t, 1, len(response.Transferred), useCase.target) {
+			assert.Equal(t, useCase.skipped, response.Transferred[0].Skipped, useCase.target)
+		}
+	}
+}
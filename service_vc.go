@@ -4,7 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/viant/toolbox"
-	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/data"
 	"github.com/viant/toolbox/url"
 	"path"
 	"strings"
@@ -26,13 +26,20 @@ const (
 	//VersionControlServicePullAction represent version control pull action
 	VersionControlServicePullAction = "pull"
 
+	//VersionControlServiceChangedAction represent version control changed action
+	VersionControlServiceChangedAction = "changed"
+
 	versionControlCredentialKey = "***vc***"
+
+	//VcStateKey represents a state key holding the first checkout origin, revision, branch and message, i.e. ${vc.revision}
+	VcStateKey = "vc"
 )
 
 type versionControlService struct {
 	*AbstractService
 	*gitService
 	*svnService
+	*goGitService
 }
 
 //checkInfo returns version control info
@@ -46,6 +53,8 @@ func (s *versionControlService) checkInfo(context *Context, request *VcStatusReq
 		return s.gitService.checkInfo(context, request)
 	case "svn":
 		return s.svnService.checkInfo(context, request)
+	case VersionControlGoGitType:
+		return s.goGitService.checkInfo(context, request)
 	}
 	return nil, fmt.Errorf("unsupported type: %v for URL %v", target.Type, target.URL)
 }
@@ -56,6 +65,9 @@ func (s *versionControlService) commit(context *Context, request *VcCommitReques
 	if err != nil {
 		return nil, err
 	}
+	if target.Type == VersionControlGoGitType {
+		return s.goGitService.commit(context, request)
+	}
 	_, err = context.Execute(target, &ExtractableCommand{
 		Executions: []*Execution{
 			{
@@ -85,6 +97,9 @@ func (s *versionControlService) pull(context *Context, request *VcPullRequest) (
 	if err != nil {
 		return nil, err
 	}
+	if target.Type == VersionControlGoGitType {
+		return s.goGitService.pull(context, request)
+	}
 	_, err = context.Execute(target, &ExtractableCommand{
 		Executions: []*Execution{
 			{
@@ -127,23 +142,51 @@ func (s *versionControlService) checkout(context *Context, request *VcCheckoutRe
 		return nil, err
 	}
 
-	for _, module := range modules {
+	for i, module := range modules {
 		var moduleOrigin = origin.Clone()
 		var targetModule = target.Clone()
 		if module != "" {
 			moduleOrigin.URL = toolbox.URLPathJoin(origin.URL, module)
 			targetModule.URL = toolbox.URLPathJoin(target.URL, module)
 		}
-		info, err := s.checkoutArtifact(context, moduleOrigin, targetModule, request.RemoveLocalChanges)
+		info, err := s.checkoutArtifact(context, request, moduleOrigin, targetModule)
 		if err != nil {
 			return nil, err
 		}
 		response.Checkouts[moduleOrigin.URL] = info
+		publishVcInfo(context, module, info, i == 0)
 	}
 	return response, nil
 }
 
-func (s *versionControlService) checkoutArtifact(context *Context, origin, target *url.Resource, removeLocalChanges bool) (*VcInfo, error) {
+//publishVcInfo publishes checkout revision, branch and message to the context state under vc key for the first checkout only, module checkout is also published under vc.modules.<module> key
+func publishVcInfo(context *Context, module string, info *VcInfo, topLevel bool) {
+	var vcState = getVcState(context)
+	var infoState = data.NewMap()
+	infoState.Put("origin", info.Origin)
+	infoState.Put("revision", info.Revision)
+	infoState.Put("branch", info.Branch)
+	infoState.Put("message", info.Message)
+	if module != "" {
+		var modules = vcState.GetMap("modules")
+		if modules == nil {
+			modules = data.NewMap()
+			vcState.Put("modules", modules)
+		}
+		modules.Put(module, infoState)
+	}
+	if !topLevel {
+		return
+	}
+	for key, value := range infoState {
+		vcState.Put(key, value)
+	}
+}
+
+func (s *versionControlService) checkoutArtifact(context *Context, request *VcCheckoutRequest, origin, target *url.Resource) (*VcInfo, error) {
+	if origin.Type == VersionControlGoGitType {
+		return s.goGitService.checkout(context, request, origin, target)
+	}
 	var directoryPath = target.DirectoryPath()
 	var parent, _ = path.Split(directoryPath)
 
@@ -156,7 +199,7 @@ func (s *versionControlService) checkoutArtifact(context *Context, origin, targe
 		return nil, err
 	}
 
-	storageService, err := storage.NewServiceForURL(target.URL, target.Credential)
+	storageService, err := getStorageService(context, target)
 	if err != nil {
 		return nil, err
 	}
@@ -179,11 +222,21 @@ func (s *versionControlService) checkoutArtifact(context *Context, origin, targe
 			s.pull(context, &VcPullRequest{
 				Origin: origin,
 				Target: target,
+				Depth:  request.Depth,
 			})
+			if !request.hasReference() && !request.Submodules {
+				return response, nil
+			}
+			switch origin.Type {
+			case "git":
+				return s.gitService.checkoutReference(context, request, origin, target, false)
+			case "svn":
+				return s.svnService.update(context, request, origin, target)
+			}
 			return response, nil
 		}
 
-		if removeLocalChanges {
+		if request.RemoveLocalChanges {
 			_, err = context.Execute(target, &ExtractableCommand{
 				Executions: []*Execution{
 					{
@@ -213,23 +266,99 @@ func (s *versionControlService) checkoutArtifact(context *Context, origin, targe
 		return nil, err
 	}
 
+	var artifactRequest = *request
+	artifactRequest.Origin = origin
+	artifactRequest.Target = target
 	switch origin.Type {
 	case "git":
-		return s.gitService.checkout(context, &VcCheckoutRequest{
-			Origin: origin,
-			Target: target,
-		})
+		return s.gitService.checkout(context, &artifactRequest)
 	case "svn":
-		return s.svnService.checkout(context, &VcCheckoutRequest{
-			Origin: origin,
-			Target: target,
-		})
+		return s.svnService.checkout(context, &artifactRequest)
 
 	default:
 		return nil, fmt.Errorf("unsupported version control type: '%v'", target.Type)
 	}
 }
 
+func getVcState(context *Context) data.Map {
+	var state = context.State()
+	var result = state.GetMap(VcStateKey)
+	if result == nil {
+		result = data.NewMap()
+		state.Put(VcStateKey, result)
+	}
+	return result
+}
+
+//changed returns paths changed since the last tested revision of the target, optionally it records the current revision as tested
+func (s *versionControlService) changed(context *Context, request *VcChangedRequest) (*VcChangedResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.checkInfo(context, &VcStatusRequest{Target: target})
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsVersionControlManaged {
+		return nil, fmt.Errorf("%v is not version control managed", target.URL)
+	}
+	var stateFile = context.Expand(request.StateFile)
+	if stateFile == "" {
+		stateFile = VcRevisionStateFile
+	}
+	revisions, err := loadVcRevisions(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %v, %v", stateFile, err)
+	}
+	var response = &VcChangedResponse{
+		Revision:     info.Revision,
+		LastRevision: revisions[target.URL],
+		Paths:        make([]string, 0),
+	}
+	if response.LastRevision != "" && response.LastRevision != response.Revision {
+		switch target.Type {
+		case "git":
+			response.Paths, err = s.gitService.changedPaths(context, target, response.LastRevision)
+		case "svn":
+			response.Paths, err = s.svnService.changedPaths(context, target, response.LastRevision, response.Revision)
+		case VersionControlGoGitType:
+			response.Paths, err = s.goGitService.changedPaths(target, response.LastRevision)
+		default:
+			err = fmt.Errorf("unsupported type: %v for URL %v", target.Type, target.URL)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var changedPaths = make(map[string]bool)
+	for _, changedPath := range response.Paths {
+		changedPaths[changedPath] = true
+	}
+	for _, files := range [][]string{info.New, info.Modified, info.Deleted, info.Untracked} {
+		for _, file := range files {
+			if !changedPaths[file] {
+				changedPaths[file] = true
+				response.Paths = append(response.Paths, file)
+			}
+		}
+	}
+	response.Changed = response.LastRevision != response.Revision || info.HasPendingChanges()
+	if request.Record && response.Revision != "" {
+		revisions[target.URL] = response.Revision
+		if err = saveVcRevisions(stateFile, revisions); err != nil {
+			return nil, fmt.Errorf("failed to save %v, %v", stateFile, err)
+		}
+	}
+	var vcState = getVcState(context)
+	vcState.Put("changed", response.Changed)
+	vcState.Put("changedPaths", response.Paths)
+	return response, nil
+}
+
 func (s *versionControlService) Run(context *Context, request interface{}) *ServiceResponse {
 	startEvent := s.Begin(context, request, Pairs("request", request))
 	var response = &ServiceResponse{Status: "ok"}
@@ -255,6 +384,15 @@ func (s *versionControlService) Run(context *Context, request interface{}) *Serv
 		if err != nil {
 			response.Error = fmt.Sprintf("failed to commit version: %v(%v), %v", actualRequest.Target.URL, actualRequest.Target.Type, err)
 		}
+	case *VcChangedRequest:
+		response.Response, err = s.changed(context, actualRequest)
+		if err != nil {
+			var targetURL string
+			if actualRequest.Target != nil {
+				targetURL = actualRequest.Target.URL
+			}
+			response.Error = fmt.Sprintf("failed to check changes: %v, %v", targetURL, err)
+		}
 	case *VcPullRequest:
 		response.Response, err = s.pull(context, actualRequest)
 		if err != nil {
@@ -314,6 +452,8 @@ func (s *versionControlService) NewRequest(action string) (interface{}, error) {
 		return &VcCommitRequest{}, nil
 	case VersionControlServicePullAction:
 		return &VcPullRequest{}, nil
+	case VersionControlServiceChangedAction:
+		return &VcChangedRequest{}, nil
 	}
 	return s.AbstractService.NewRequest(action)
 }
@@ -326,6 +466,7 @@ func NewVersionControlService() Service {
 			VersionControlServiceStatusAction,
 			VersionControlServiceCommitAction,
 			VersionControlServicePullAction,
+			VersionControlServiceChangedAction,
 		),
 		gitService: &gitService{},
 		svnService: &svnService{},
@@ -0,0 +1,63 @@
+package endly
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+//VcRevisionStateFile represents default local file keeping the last tested revision per target URL
+var VcRevisionStateFile = path.Join(os.Getenv("HOME"), ".endly", "vc", "revisions.json")
+
+//VcChangedRequest represents a request to detect changes since the last tested revision, detection result is published to the state as ${vc.changed} and ${vc.changedPaths}
+type VcChangedRequest struct {
+	Target    *url.Resource //local code source repo
+	StateFile string        //local file keeping the last tested revision per target, VcRevisionStateFile by default
+	Record    bool          //flag to record current revision as the last tested one, i.e. once build and tests succeeded
+}
+
+//VcChangedResponse represents a changed response
+type VcChangedResponse struct {
+	Changed      bool     //true if there was no tested revision, revision changed or there are pending local changes
+	Revision     string   //current revision
+	LastRevision string   //last tested revision
+	Paths        []string //paths changed since the last tested revision including pending local changes
+}
+
+//Validate checks if request is valid
+func (r *VcChangedRequest) Validate() error {
+	if r.Target == nil {
+		return errors.New("target was empty")
+	}
+	return nil
+}
+
+//loadVcRevisions loads revisions keyed by target URL from the state file, missing file returns empty revisions
+func loadVcRevisions(stateFile string) (map[string]string, error) {
+	var result = make(map[string]string)
+	content, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == 0 {
+		return result, nil
+	}
+	return result, json.Unmarshal(content, &result)
+}
+
+func saveVcRevisions(stateFile string, revisions map[string]string) error {
+	if err := os.MkdirAll(path.Dir(stateFile), 0744); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(revisions, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(stateFile, content, 0644)
+}
@@ -13,6 +13,11 @@ type VcCheckoutRequest struct {
 	Target             *url.Resource //local code destination
 	Modules            []string      //vc path to project
 	RemoveLocalChanges bool          //flag to remove local changes
+	Branch             string        //git branch to checkout, default branch if empty
+	Tag                string        //git tag to checkout
+	Revision           string        //commit sha, svn revision or git ref i.e. refs/pull/12/head, it takes precedence over branch and tag
+	Depth              int           //git shallow clone depth, full history if zero
+	Submodules         bool          //flag to init and update git submodules recursively
 }
 
 //VcCheckoutResponse represents checkout response
@@ -42,6 +47,17 @@ func (r *VcCheckoutRequest) Validate() error {
 	if r.Target.Type == "" {
 		r.Target.Type = r.Origin.Type
 	}
+	if r.Branch != "" && r.Tag != "" {
+		return fmt.Errorf("branch and tag were both specified: %v, %v", r.Branch, r.Tag)
+	}
+	if r.Origin.Type == "svn" && (r.Branch != "" || r.Tag != "") {
+		return fmt.Errorf("branch and tag are not supported for svn, use origin URL instead: %v", r.Origin.URL)
+	}
 
 	return nil
 }
+
+//hasReference returns true if request specifies branch, tag or revision
+func (r *VcCheckoutRequest) hasReference() bool {
+	return r.Branch != "" || r.Tag != "" || r.Revision != ""
+}
@@ -6,6 +6,8 @@ import (
 
 //VcCommitRequest represents a commit request
 type VcCommitRequest struct {
-	Target  *url.Resource //local code source repo
+	Target  *url.Resource //local code source repo, credential is used to push to origin
 	Message string        //commit message
+	Author  string        //optional commit author name, git config user.name by default
+	Email   string        //optional commit author email, git config user.email by default
 }
@@ -100,6 +100,9 @@ func (s *gitService) checkInfo(context *Context, request *VcStatusRequest) (*VcI
 			{
 				Command: fmt.Sprintf("git rev-parse HEAD"),
 			},
+			{
+				Command: "git log -1 --pretty=%B",
+			},
 		},
 	})
 	if err != nil {
@@ -118,6 +121,7 @@ func (s *gitService) checkInfo(context *Context, request *VcStatusRequest) (*VcI
 	result.IsVersionControlManaged = true
 	extractGitStatus(response.Stdout(0), result)
 	extractRevision(response.Stdout(2), result)
+	result.Message = strings.TrimSpace(vtclean.Clean(response.Stdout(3), false))
 	return result, nil
 }
 
@@ -135,7 +139,11 @@ func (s *gitService) pull(context *Context, request *VcPullRequest) (*VcInfo, er
 	if err != nil {
 		return nil, err
 	}
-	return s.runSecureCommand(context, origin, target, "git pull")
+	var command = "git pull"
+	if request.Depth > 0 {
+		command += fmt.Sprintf(" --depth %v", request.Depth)
+	}
+	return s.runSecureCommand(context, origin, target, command)
 }
 
 func (s *gitService) checkout(context *Context, request *VcCheckoutRequest) (*VcInfo, error) {
@@ -156,7 +164,65 @@ func (s *gitService) checkout(context *Context, request *VcCheckoutRequest) (*Vc
 	if err != nil {
 		return nil, err
 	}
-	return s.runSecureCommand(context, origin, target, fmt.Sprintf("git clone %v %v", origin.CredentialURL(username, ""), projectDir))
+	var options = ""
+	if request.Depth > 0 {
+		options += fmt.Sprintf(" --depth %v", request.Depth)
+	}
+	if request.Revision == "" {
+		if request.Branch != "" {
+			options += " --branch " + request.Branch
+		} else if request.Tag != "" {
+			options += " --branch " + request.Tag
+		}
+	}
+	info, err := s.runSecureCommand(context, origin, target, fmt.Sprintf("git clone%v %v %v", options, origin.CredentialURL(username, ""), projectDir))
+	if err != nil || (request.Revision == "" && !request.Submodules) {
+		return info, err
+	}
+	return s.checkoutReference(context, request, origin, target, true)
+}
+
+//checkoutReference checks out requested revision, branch or tag in the target repository and updates submodules; cloned repository has already branch or tag checked out
+func (s *gitService) checkoutReference(context *Context, request *VcCheckoutRequest, origin, target *url.Resource, cloned bool) (*VcInfo, error) {
+	_, err := context.Execute(target, fmt.Sprintf("cd %v", target.DirectoryPath()))
+	if err != nil {
+		return nil, err
+	}
+	var reference = request.Revision
+	if reference == "" && !cloned {
+		reference = request.Branch
+		if reference == "" {
+			reference = request.Tag
+		}
+	}
+	var depth = ""
+	if request.Depth > 0 {
+		depth = fmt.Sprintf(" --depth %v", request.Depth)
+	}
+	if reference != "" {
+		if _, err = s.runSecureCommand(context, origin, target, fmt.Sprintf("git fetch%v origin %v", depth, reference)); err != nil {
+			return nil, err
+		}
+		var command = "git checkout FETCH_HEAD"
+		if request.Revision == "" && request.Branch != "" {
+			command = fmt.Sprintf("git checkout -B %v FETCH_HEAD", request.Branch)
+		}
+		_, err = context.Execute(target, &ExtractableCommand{
+			Executions: []*Execution{
+				{
+					Command: command,
+					Error:   []string{"error:", "fatal:"},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if request.Submodules {
+		return s.runSecureCommand(context, origin, target, fmt.Sprintf("git submodule update --init --recursive%v", depth))
+	}
+	return s.checkInfo(context, &VcStatusRequest{Target: target})
 }
 
 func (s *gitService) runSecureCommand(context *Context, origin, target *url.Resource, command string) (info *VcInfo, err error) {
@@ -214,11 +280,14 @@ func (s *gitService) commit(context *Context, request *VcCommitRequest) (*VcInfo
 		}
 	}
 
-	message := strings.Replace(request.Message, "\"", "'", len(request.Message))
+	var author = ""
+	if request.Author != "" && request.Email != "" {
+		author = " --author=" + quoteShellArgument(fmt.Sprintf("%v <%v>", request.Author, request.Email))
+	}
 	_, err = context.Execute(request.Target, &ExtractableCommand{
 		Executions: []*Execution{
 			{
-				Command: fmt.Sprintf("git commit -m \"%v\" -a", message),
+				Command: fmt.Sprintf("git commit -m %v -a%v", quoteShellArgument(request.Message), author),
 				Error:   []string{"No such file or directory", "Error"},
 			},
 		},
@@ -239,3 +308,29 @@ func (s *gitService) commit(context *Context, request *VcCommitRequest) (*VcInfo
 		Target: request.Target,
 	})
 }
+
+//changedPaths returns paths changed between supplied revision and HEAD
+func (s *gitService) changedPaths(context *Context, target *url.Resource, revision string) ([]string, error) {
+	response, err := context.Execute(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("cd %v", target.DirectoryPath()),
+			},
+			{
+				Command: fmt.Sprintf("git --no-pager diff --name-only %v HEAD", revision),
+				Error:   []string{"fatal:", "unknown revision"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	for _, line := range strings.Split(response.Stdout(0), "\n") {
+		line = strings.TrimSpace(vtclean.Clean(line, false))
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
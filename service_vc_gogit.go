@@ -0,0 +1,382 @@
+package endly
+
+import (
+	"errors"
+	"fmt"
+	"github.com/viant/toolbox/cred"
+	"github.com/viant/toolbox/url"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	format "gopkg.in/src-d/go-git.v4/plumbing/format/config"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//VersionControlGoGitType represents git version control type handled in process with pure Go git library, no git CLI is needed, only local targets are supported
+const VersionControlGoGitType = "go-git"
+
+type goGitService struct{}
+
+//localPath returns target directory path, target needs to be a local file system resource
+func (s *goGitService) localPath(target *url.Resource) (string, error) {
+	var scheme = target.ParsedURL.Scheme
+	var hostname = target.ParsedURL.Hostname()
+	if scheme != "file" && hostname != "127.0.0.1" && hostname != "localhost" {
+		return "", fmt.Errorf("%v supports only local targets: %v", VersionControlGoGitType, target.URL)
+	}
+	return target.DirectoryPath(), nil
+}
+
+//auth returns transport auth for origin credential
+func (s *goGitService) auth(origin *url.Resource) (transport.AuthMethod, error) {
+	return s.remoteAuth(origin.URL, origin.Credential)
+}
+
+//remoteAuth returns transport auth for remote URL credential, basic auth is used for http and https, public keys or password for ssh, local remotes need no auth
+func (s *goGitService) remoteAuth(remoteURL, credential string) (transport.AuthMethod, error) {
+	if credential == "" || strings.HasPrefix(remoteURL, "file://") || strings.HasPrefix(remoteURL, "/") {
+		return nil, nil
+	}
+	config, err := cred.NewConfig(credential)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(remoteURL, "http://") || strings.HasPrefix(remoteURL, "https://") {
+		return &http.BasicAuth{Username: config.Username, Password: config.Password}, nil
+	}
+	if config.PrivateKeyPath != "" {
+		return ssh.NewPublicKeysFromFile(config.Username, config.PrivateKeyPath, config.Password)
+	}
+	return &ssh.Password{User: config.Username, Password: config.Password}, nil
+}
+
+//author returns commit signature with request author and email, missing values are taken from repository and then global git config user section
+func (s *goGitService) author(repository *git.Repository, request *VcCommitRequest) (*object.Signature, error) {
+	var result = &object.Signature{Name: request.Author, Email: request.Email, When: time.Now()}
+	var userSections = make([]*format.Section, 0)
+	if repositoryConfig, err := repository.Config(); err == nil && repositoryConfig.Raw != nil {
+		userSections = append(userSections, repositoryConfig.Raw.Section("user"))
+	}
+	if file, err := os.Open(path.Join(os.Getenv("HOME"), ".gitconfig")); err == nil {
+		var globalConfig = format.New()
+		if err = format.NewDecoder(file).Decode(globalConfig); err == nil {
+			userSections = append(userSections, globalConfig.Section("user"))
+		}
+		_ = file.Close()
+	}
+	for _, section := range userSections {
+		if result.Name == "" {
+			result.Name = section.Option("name")
+		}
+		if result.Email == "" {
+			result.Email = section.Option("email")
+		}
+	}
+	if result.Name == "" || result.Email == "" {
+		return nil, errors.New("commit author was empty, set request Author and Email or git config user.name and user.email")
+	}
+	return result, nil
+}
+
+func (s *goGitService) open(target *url.Resource) (*git.Repository, error) {
+	directory, err := s.localPath(target)
+	if err != nil {
+		return nil, err
+	}
+	return git.PlainOpen(directory)
+}
+
+func (s *goGitService) checkInfo(context *Context, request *VcStatusRequest) (*VcInfo, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := s.open(target)
+	if err == git.ErrRepositoryNotExists {
+		return &VcInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.info(repository)
+}
+
+//info returns version control info for supplied repository
+func (s *goGitService) info(repository *git.Repository) (*VcInfo, error) {
+	var result = &VcInfo{
+		IsVersionControlManaged: true,
+		New:                     make([]string, 0),
+		Modified:                make([]string, 0),
+		Deleted:                 make([]string, 0),
+		Untracked:               make([]string, 0),
+	}
+	if remote, err := repository.Remote(git.DefaultRemoteName); err == nil && len(remote.Config().URLs) > 0 {
+		result.Origin = remote.Config().URLs[0]
+	}
+	if head, err := repository.Head(); err == nil {
+		result.Revision = head.Hash().String()
+		if head.Name().IsBranch() {
+			result.Branch = head.Name().Short()
+		}
+		if commit, err := repository.CommitObject(head.Hash()); err == nil {
+			result.Message = strings.TrimSpace(commit.Message)
+		}
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+	result.IsUptoDate = status.IsClean()
+	for file, fileStatus := range status {
+		switch {
+		case fileStatus.Worktree == git.Untracked:
+			result.Untracked = append(result.Untracked, file)
+		case fileStatus.Staging == git.Added:
+			result.New = append(result.New, file)
+		case fileStatus.Staging == git.Deleted || fileStatus.Worktree == git.Deleted:
+			result.Deleted = append(result.Deleted, file)
+		case fileStatus.Staging == git.Modified || fileStatus.Worktree == git.Modified:
+			result.Modified = append(result.Modified, file)
+		}
+	}
+	for _, files := range [][]string{result.New, result.Modified, result.Deleted, result.Untracked} {
+		sort.Strings(files)
+	}
+	return result, nil
+}
+
+func (s *goGitService) pull(context *Context, request *VcPullRequest) (*VcInfo, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	origin, err := context.ExpandResource(request.Origin)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := s.open(target)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.auth(origin)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return nil, err
+	}
+	if !head.Name().IsBranch() {
+		//detached head stays on its commit, only remote branches are fetched
+		err = repository.Fetch(&git.FetchOptions{RemoteName: git.DefaultRemoteName, Auth: auth, Depth: request.Depth})
+	} else {
+		err = worktree.Pull(&git.PullOptions{RemoteName: git.DefaultRemoteName, ReferenceName: head.Name(), Auth: auth, Depth: request.Depth})
+	}
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, checkVersionControlAuthErrors(err, origin)
+	}
+	return s.info(repository)
+}
+
+//checkout clones origin into target directory, existing repository with matching origin is only pulled, then requested reference is checked out
+func (s *goGitService) checkout(context *Context, request *VcCheckoutRequest, origin, target *url.Resource) (*VcInfo, error) {
+	directory, err := s.localPath(target)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.auth(origin)
+	if err != nil {
+		return nil, err
+	}
+	if repository, err := git.PlainOpen(directory); err == nil {
+		info, err := s.info(repository)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSuffix(info.Origin, ".git") == strings.TrimSuffix(origin.URL, ".git") {
+			if !request.hasReference() {
+				if info, err = s.pull(context, &VcPullRequest{Origin: origin, Target: target, Depth: request.Depth}); err != nil || !request.Submodules {
+					return info, err
+				}
+			}
+			return s.checkoutReference(repository, request, auth, false)
+		}
+		if !request.RemoveLocalChanges {
+			return nil, fmt.Errorf("directory contains incompatible repo: %v %v", info.Origin, origin.URL)
+		}
+		if err = os.RemoveAll(directory); err != nil {
+			return nil, err
+		}
+	}
+	if err = os.MkdirAll(path.Dir(directory), 0755); err != nil {
+		return nil, err
+	}
+	var options = &git.CloneOptions{URL: origin.URL, Auth: auth, Depth: request.Depth}
+	if request.Revision == "" {
+		if request.Branch != "" {
+			options.ReferenceName = plumbing.NewBranchReferenceName(request.Branch)
+		} else if request.Tag != "" {
+			options.ReferenceName = plumbing.NewTagReferenceName(request.Tag)
+		}
+	}
+	if request.Submodules {
+		options.RecurseSubmodules = git.DefaultSubmoduleRecursionDepth
+	}
+	repository, err := git.PlainClone(directory, false, options)
+	if err != nil {
+		return nil, checkVersionControlAuthErrors(err, origin)
+	}
+	if request.Revision == "" {
+		return s.info(repository)
+	}
+	return s.checkoutReference(repository, request, auth, true)
+}
+
+//checkoutReference fetches and checks out requested revision, branch or tag as detached head and updates submodules, cloned repository has already branch or tag checked out
+func (s *goGitService) checkoutReference(repository *git.Repository, request *VcCheckoutRequest, auth transport.AuthMethod, cloned bool) (*VcInfo, error) {
+	var revision = request.Revision
+	if revision == "" && !cloned {
+		if request.Branch != "" {
+			revision = plumbing.NewRemoteReferenceName(git.DefaultRemoteName, request.Branch).String()
+		} else {
+			revision = request.Tag
+		}
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	if revision != "" {
+		var refSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%v/*", git.DefaultRemoteName))}
+		if strings.HasPrefix(request.Revision, "refs/") {
+			refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("+%v:%v", request.Revision, request.Revision)))
+		}
+		err = repository.Fetch(&git.FetchOptions{RemoteName: git.DefaultRemoteName, Auth: auth, Depth: request.Depth, RefSpecs: refSpecs, Tags: git.AllTags})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return nil, err
+		}
+		hash, err := repository.ResolveRevision(plumbing.Revision(revision))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %v, %v", revision, err)
+		}
+		if err = worktree.Checkout(&git.CheckoutOptions{Hash: *hash}); err != nil {
+			return nil, err
+		}
+	}
+	if request.Submodules {
+		submodules, err := worktree.Submodules()
+		if err != nil {
+			return nil, err
+		}
+		err = submodules.Update(&git.SubmoduleUpdateOptions{Init: true, RecurseSubmodules: git.DefaultSubmoduleRecursionDepth, Auth: auth})
+		if err != nil {
+			return nil, err
+		}
+	}
+	info, err := s.info(repository)
+	if err == nil && info.Branch == "" {
+		info.Branch = request.Branch
+	}
+	return info, err
+}
+
+func (s *goGitService) commit(context *Context, request *VcCommitRequest) (*VcInfo, error) {
+	target, err := context.ExpandResource(request.Target)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := s.open(target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.info(repository)
+	if err != nil {
+		return nil, err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range info.Untracked {
+		if _, err = worktree.Add(file); err != nil {
+			return nil, err
+		}
+	}
+	author, err := s.author(repository, request)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.remoteAuth(info.Origin, target.Credential)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = worktree.Commit(request.Message, &git.CommitOptions{All: true, Author: author}); err != nil {
+		return nil, err
+	}
+	err = repository.Push(&git.PushOptions{RemoteName: git.DefaultRemoteName, Auth: auth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, checkVersionControlAuthErrors(err, target)
+	}
+	return s.info(repository)
+}
+
+//changedPaths returns paths changed between supplied revision and HEAD
+func (s *goGitService) changedPaths(target *url.Resource, revision string) ([]string, error) {
+	repository, err := s.open(target)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repository.Head()
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	revisionHash, err := repository.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %v, %v", revision, err)
+	}
+	revisionCommit, err := repository.CommitObject(*revisionHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup revision %v, %v", revision, err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	revisionTree, err := revisionCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(revisionTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	for _, change := range changes {
+		var name = change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
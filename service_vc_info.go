@@ -6,6 +6,7 @@ type VcInfo struct {
 	Origin                  string //Origin URL
 	Revision                string //Origin Revision
 	Branch                  string //current branch
+	Message                 string //last commit message
 	IsUptoDate              bool
 	New                     []string //new files
 	Untracked               []string //untracked files
@@ -9,6 +9,7 @@ import (
 type VcPullRequest struct {
 	Target *url.Resource //local code destination
 	Origin *url.Resource //version control origin
+	Depth  int           //git shallow fetch depth, full history if zero
 }
 
 //Validate checks if request is valud
@@ -101,9 +101,20 @@ func (s *svnService) checkout(context *Context, request *VcCheckoutRequest) (*Vc
 	if err != nil {
 		return nil, err
 	}
+	if request.Revision != "" {
+		return s.runSecureSvnCommand(context, target, request.Origin, "co", "-r", request.Revision, request.Origin.URL, target.DirectoryPath())
+	}
 	return s.runSecureSvnCommand(context, target, request.Origin, "co", request.Origin.URL, target.DirectoryPath())
 }
 
+//update updates existing working copy to the requested revision
+func (s *svnService) update(context *Context, request *VcCheckoutRequest, origin, target *url.Resource) (*VcInfo, error) {
+	if request.Revision == "" {
+		return s.checkInfo(context, &VcStatusRequest{Target: target})
+	}
+	return s.runSecureSvnCommand(context, target, origin, "up", "-r", request.Revision)
+}
+
 func (s *svnService) runSecureSvnCommand(context *Context, target *url.Resource, origin *url.Resource, command string, arguments ...string) (*VcInfo, error) {
 	username, _, err := origin.LoadCredential(true)
 	if err != nil {
@@ -162,3 +173,30 @@ func (s *svnService) commit(context *Context, request *VcCommitRequest) (*VcInfo
 		Target: request.Target,
 	})
 }
+
+//changedPaths returns paths changed between supplied revisions
+func (s *svnService) changedPaths(context *Context, target *url.Resource, fromRevision, toRevision string) ([]string, error) {
+	response, err := context.Execute(target, &ExtractableCommand{
+		Executions: []*Execution{
+			{
+				Command: fmt.Sprintf("cd %v", target.ParsedURL.Path),
+			},
+			{
+				Command: fmt.Sprintf("svn diff --summarize -r %v:%v", fromRevision, toRevision),
+				Error:   []string{"No such revision", "is not a working copy"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	for _, line := range strings.Split(response.Stdout(0), "\n") {
+		columns, ok := ExtractColumns(strings.TrimSpace(line))
+		if !ok || len(columns) < 2 {
+			continue
+		}
+		result = append(result, columns[len(columns)-1])
+	}
+	return result, nil
+}
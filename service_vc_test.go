@@ -4,9 +4,19 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/endly"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"io/ioutil"
+	"os"
+	"path"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestVc_Status(t *testing.T) {
@@ -188,6 +198,7 @@ func TestVc_Checkout(t *testing.T) {
 						IsVersionControlManaged: true,
 						IsUptoDate:              true,
 						Branch:                  "master",
+						Message:                 "initial commit",
 						Modified:                []string{},
 					},
 				},
@@ -207,6 +218,7 @@ func TestVc_Checkout(t *testing.T) {
 						IsVersionControlManaged: true,
 						IsUptoDate:              false,
 						Branch:                  "master",
+						Message:                 "initial commit",
 						Modified:                []string{"README.md"},
 					},
 				},
@@ -256,6 +268,13 @@ func TestVc_Checkout(t *testing.T) {
 					assert.Equal(t, expected.Origin, actual.Origin, "Origin "+baseCase)
 					assert.Equal(t, expected.Branch, actual.Branch, "Branch "+baseCase)
 					assert.Equal(t, expected.Modified, actual.Modified, "Modified "+baseCase)
+					if expected.Message != "" {
+						assert.Equal(t, expected.Message, actual.Message, "Message "+baseCase)
+					}
+					var vcState = context.State().GetMap(endly.VcStateKey)
+					if assert.NotNil(t, vcState, baseCase) {
+						assert.Equal(t, expected.Branch, vcState.GetString("branch"), "State branch "+baseCase)
+					}
 				}
 
 			}
@@ -263,6 +282,153 @@ func TestVc_Checkout(t *testing.T) {
 	}
 }
 
+func TestVc_CheckoutReference(t *testing.T) {
+	credentialFile, err := GetCredential("scp.json", "awitas", "***")
+	assert.Nil(t, err)
+	gitCredentialFile, err := GetCredential("git.json", "adrianwit", "***")
+	assert.Nil(t, err)
+	//existing working copies, checkout of missing target clones origin
+	memStorage := storage.NewMemoryService()
+	memStorage.Upload("scp://127.0.0.1:22/tmp/myproj/.git/HEAD", strings.NewReader("ref: refs/heads/master"))
+	memStorage.Upload("scp://127.0.0.1:22/tmp/project2/trunk/.svn/wc.db", strings.NewReader("svn"))
+	var gitOrigin = "https://github.com/adrianwit/projectA"
+	var manager = endly.NewManager()
+	var useCases = []struct {
+		baseDir  string
+		Request  *endly.VcCheckoutRequest
+		Revision string
+		Branch   string
+	}{
+		{
+			"test/vc/git/checkout/branch/linux",
+			&endly.VcCheckoutRequest{
+				Target: url.NewResource("scp://127.0.0.1:22/tmp/branchproj", credentialFile),
+				Origin: url.NewResource(gitOrigin, gitCredentialFile),
+				Branch: "develop",
+				Depth:  1,
+			},
+			"ab431e29dd36c32fdac1205cc12ce849812b9e7c",
+			"develop",
+		},
+		{
+			"test/vc/git/checkout/revision/linux",
+			&endly.VcCheckoutRequest{
+				Target:   url.NewResource("scp://127.0.0.1:22/tmp/revproj", credentialFile),
+				Origin:   url.NewResource(gitOrigin, gitCredentialFile),
+				Revision: "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+				Depth:    1,
+			},
+			"0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+			"",
+		},
+		{
+			"test/vc/git/checkout/reset/linux",
+			&endly.VcCheckoutRequest{
+				Target: url.NewResource("scp://127.0.0.1:22/tmp/myproj", credentialFile),
+				Origin: url.NewResource(gitOrigin, gitCredentialFile),
+				Branch: "develop",
+			},
+			"0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+			"develop",
+		},
+		{
+			"test/vc/git/checkout/submodules/linux",
+			&endly.VcCheckoutRequest{
+				Target:     url.NewResource("scp://127.0.0.1:22/tmp/myproj", credentialFile),
+				Origin:     url.NewResource(gitOrigin, gitCredentialFile),
+				Submodules: true,
+			},
+			"ab431e29dd36c32fdac1205cc12ce849812b9e7c",
+			"master",
+		},
+		{
+			"test/vc/svn/checkout/revision/linux",
+			&endly.VcCheckoutRequest{
+				Target:   url.NewResource("scp://127.0.0.1:22/tmp/project1/trunk", credentialFile),
+				Origin:   url.NewResource("http://svn.viant.com/svn/projects/project1/trunk", credentialFile),
+				Revision: "42",
+			},
+			"42",
+			"trunk",
+		},
+		{
+			"test/vc/svn/checkout/update/linux",
+			&endly.VcCheckoutRequest{
+				Target:   url.NewResource("scp://127.0.0.1:22/tmp/project2/trunk", credentialFile),
+				Origin:   url.NewResource("http://svn.viant.com/svn/projects/project2/trunk", credentialFile),
+				Revision: "42",
+			},
+			"42",
+			"trunk",
+		},
+	}
+	for _, useCase := range useCases {
+		execService, err := GetReplayService(useCase.baseDir)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			continue
+		}
+		context, err := OpenTestContext(manager, useCase.Request.Target, execService)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			continue
+		}
+		context.State().Put(endly.UseMemoryService, true)
+		service, err := context.Service(endly.VersionControlServiceID)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			context.Close()
+			continue
+		}
+		serviceResponse := service.Run(context, useCase.Request)
+		context.Close()
+		if !assert.Equal(t, "", serviceResponse.Error, useCase.baseDir) {
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.VcCheckoutResponse)
+		if !assert.True(t, ok, useCase.baseDir) {
+			continue
+		}
+		info, ok := response.Checkouts[useCase.Request.Origin.URL]
+		if assert.True(t, ok, useCase.baseDir) {
+			assert.Equal(t, useCase.Revision, info.Revision, useCase.baseDir)
+			assert.Equal(t, useCase.Branch, info.Branch, useCase.baseDir)
+		}
+		var vcState = context.State().GetMap(endly.VcStateKey)
+		if assert.NotNil(t, vcState, useCase.baseDir) {
+			assert.Equal(t, useCase.Revision, vcState.GetString("revision"), useCase.baseDir)
+		}
+	}
+}
+
+func TestVcCheckoutRequest_Validate(t *testing.T) {
+	{
+		var request = &endly.VcCheckoutRequest{
+			Origin: url.NewResource("https://github.com/viant/endly"),
+			Target: url.NewResource("/tmp/endly"),
+			Branch: "master",
+			Tag:    "v1.0",
+		}
+		request.Origin.Type = "git"
+		assert.NotNil(t, request.Validate())
+	}
+	{
+		var request = &endly.VcCheckoutRequest{
+			Origin: url.NewResource("http://svn.viant.com/svn/projects/project1/trunk"),
+			Target: url.NewResource("/tmp/project1"),
+			Branch: "release",
+		}
+		assert.NotNil(t, request.Validate())
+	}
+	{
+		var request = &endly.VcCheckoutRequest{
+			Origin:   url.NewResource("https://github.com/viant/endly"),
+			Target:   url.NewResource("/tmp/endly"),
+			Revision: "refs/pull/12/head",
+			Depth:    1,
+		}
+		assert.Nil(t, request.Validate())
+		assert.Equal(t, "git", request.Target.Type)
+	}
+}
+
 ////
 //func TestService_Run2StatusRequest(t *testing.T) {
 //
@@ -305,3 +471,365 @@ func TestVc_Checkout(t *testing.T) {
 //	//assert.True(t, info.HasPendingChanges())
 //
 //}
+
+func TestVc_GoGitStatus(t *testing.T) {
+	var manager = endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.VersionControlServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	directory, err := ioutil.TempDir("", "gogit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+
+	var target = url.NewResource("file://" + directory)
+	target.Type = endly.VersionControlGoGitType
+	serviceResponse := service.Run(context, &endly.VcStatusRequest{Target: target})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.VcInfo)
+		if assert.True(t, ok) {
+			assert.False(t, info.IsVersionControlManaged)
+		}
+	}
+
+	var remoteTarget = url.NewResource("scp://10.0.0.1:22/project")
+	remoteTarget.Type = endly.VersionControlGoGitType
+	serviceResponse = service.Run(context, &endly.VcStatusRequest{Target: remoteTarget})
+	assert.True(t, strings.Contains(serviceResponse.Error, "supports only local targets"), serviceResponse.Error)
+}
+
+func TestVc_Changed(t *testing.T) {
+	directory, err := ioutil.TempDir("", "changed")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	repository, err := git.PlainInit(path.Join(directory, "repo"), false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	worktree, err := repository.Worktree()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var commitFile = func(name string) {
+		_ = ioutil.WriteFile(path.Join(directory, "repo", name), []byte(name), 0644)
+		_, err = worktree.Add(name)
+		assert.Nil(t, err)
+		_, err = worktree.Commit("add "+name, &git.CommitOptions{Author: &object.Signature{Name: "endly", When: time.Now()}})
+		assert.Nil(t, err)
+	}
+	commitFile("README.md")
+
+	var manager = endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.VersionControlServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("file://" + path.Join(directory, "repo"))
+	target.Type = endly.VersionControlGoGitType
+	var request = &endly.VcChangedRequest{
+		Target:    target,
+		StateFile: path.Join(directory, "revisions.json"),
+		Record:    true,
+	}
+
+	serviceResponse := service.Run(context, request)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.VcChangedResponse)
+		if assert.True(t, ok) {
+			assert.True(t, response.Changed)
+			assert.Equal(t, "", response.LastRevision)
+		}
+	}
+
+	serviceResponse = service.Run(context, request)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.VcChangedResponse)
+		if assert.True(t, ok) {
+			assert.False(t, response.Changed)
+			assert.Equal(t, response.Revision, response.LastRevision)
+		}
+		assert.Equal(t, false, context.State().GetMap(endly.VcStateKey).Get("changed"))
+	}
+
+	commitFile("app.go")
+	serviceResponse = service.Run(context, request)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.VcChangedResponse)
+		if assert.True(t, ok) {
+			assert.True(t, response.Changed)
+			assert.Equal(t, []string{"app.go"}, response.Paths)
+		}
+	}
+
+	//last tested revision is resolved as git revision, i.e. tag name
+	head, err := repository.Head()
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = repository.CreateTag("tested", head.Hash(), nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	commitFile("main.go")
+	_ = ioutil.WriteFile(request.StateFile, []byte(fmt.Sprintf(`{"%v":"tested"}`, target.URL)), 0644)
+	serviceResponse = service.Run(context, &endly.VcChangedRequest{Target: target, StateFile: request.StateFile})
+	if assert.Equal(t, "", serviceResponse.Error) {
+		response, ok := serviceResponse.Response.(*endly.VcChangedResponse)
+		if assert.True(t, ok) {
+			assert.True(t, response.Changed)
+			assert.Equal(t, []string{"main.go"}, response.Paths)
+		}
+	}
+}
+
+func TestVc_ChangedCommands(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	directory, err := ioutil.TempDir("", "changedcommands")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	var manager = endly.NewManager()
+	var useCases = []struct {
+		baseDir      string
+		Type         string
+		URL          string
+		LastRevision string
+		Revision     string
+		Paths        []string
+	}{
+		{
+			"test/vc/git/changed/linux",
+			"git",
+			"scp://127.0.0.1:22/tmp/myproj",
+			"ab431e29dd36c32fdac1205cc12ce849812b9e7c",
+			"0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+			[]string{"app.go", "lib/util.go", "README.md"},
+		},
+		{
+			"test/vc/svn/changed/linux",
+			"svn",
+			"scp://127.0.0.1:22/tmp/project1/trunk",
+			"42",
+			"45",
+			[]string{"src/main.go", "README.md", "pom.xml"},
+		},
+	}
+	for _, useCase := range useCases {
+		var target = url.NewResource(useCase.URL, credentialFile)
+		target.Type = useCase.Type
+		var stateFile = path.Join(directory, useCase.Type+".json")
+		_ = ioutil.WriteFile(stateFile, []byte(fmt.Sprintf(`{"%v":"%v"}`, useCase.URL, useCase.LastRevision)), 0644)
+		execService, err := GetReplayService(useCase.baseDir)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			continue
+		}
+		context, err := OpenTestContext(manager, target, execService)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			continue
+		}
+		service, err := context.Service(endly.VersionControlServiceID)
+		if !assert.Nil(t, err, useCase.baseDir) {
+			context.Close()
+			continue
+		}
+		serviceResponse := service.Run(context, &endly.VcChangedRequest{Target: target, StateFile: stateFile})
+		context.Close()
+		if !assert.Equal(t, "", serviceResponse.Error, useCase.baseDir) {
+			continue
+		}
+		response, ok := serviceResponse.Response.(*endly.VcChangedResponse)
+		if assert.True(t, ok, useCase.baseDir) {
+			assert.True(t, response.Changed, useCase.baseDir)
+			assert.Equal(t, useCase.LastRevision, response.LastRevision, useCase.baseDir)
+			assert.Equal(t, useCase.Revision, response.Revision, useCase.baseDir)
+			assert.Equal(t, useCase.Paths, response.Paths, useCase.baseDir)
+		}
+	}
+}
+
+func TestVc_GoGitPull(t *testing.T) {
+	directory, err := ioutil.TempDir("", "gogitpull")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	var originDirectory = path.Join(directory, "origin")
+	origin, err := git.PlainInit(originDirectory, false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	originWorktree, err := origin.Worktree()
+	if !assert.Nil(t, err) {
+		return
+	}
+	var commitFile = func(name string) plumbing.Hash {
+		_ = ioutil.WriteFile(path.Join(originDirectory, name), []byte(name), 0644)
+		_, err = originWorktree.Add(name)
+		assert.Nil(t, err)
+		hash, err := originWorktree.Commit("add "+name, &git.CommitOptions{Author: &object.Signature{Name: "endly", When: time.Now()}})
+		assert.Nil(t, err)
+		return hash
+	}
+	commitFile("README.md")
+	var repositoryDirectory = path.Join(directory, "repo")
+	repository, err := git.PlainClone(repositoryDirectory, false, &git.CloneOptions{URL: originDirectory})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	var manager = endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.VersionControlServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("file://" + repositoryDirectory)
+	target.Type = endly.VersionControlGoGitType
+	var request = &endly.VcPullRequest{
+		Target: target,
+		Origin: url.NewResource(originDirectory),
+	}
+
+	//branch head is pulled
+	var pulled = commitFile("app.go")
+	serviceResponse := service.Run(context, request)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.VcInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, pulled.String(), info.Revision)
+		}
+	}
+
+	//detached head stays on its commit, remote branch is fetched
+	worktree, err := repository.Worktree()
+	if !assert.Nil(t, err) {
+		return
+	}
+	if !assert.Nil(t, worktree.Checkout(&git.CheckoutOptions{Hash: pulled})) {
+		return
+	}
+	var fetched = commitFile("main.go")
+	serviceResponse = service.Run(context, request)
+	if assert.Equal(t, "", serviceResponse.Error) {
+		info, ok := serviceResponse.Response.(*endly.VcInfo)
+		if assert.True(t, ok) {
+			assert.EqualValues(t, pulled.String(), info.Revision)
+		}
+	}
+	reference, err := repository.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "master"), true)
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, fetched.String(), reference.Hash().String())
+	}
+}
+
+func TestVc_GoGitCommit(t *testing.T) {
+	directory, err := ioutil.TempDir("", "gogitcommit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	var originDirectory = path.Join(directory, "origin.git")
+	origin, err := git.PlainInit(originDirectory, true)
+	if !assert.Nil(t, err) {
+		return
+	}
+	repository, err := git.PlainInit(path.Join(directory, "repo"), false)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = repository.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{originDirectory}})
+	if !assert.Nil(t, err) {
+		return
+	}
+	_ = ioutil.WriteFile(path.Join(directory, "repo", "README.md"), []byte("endly"), 0644)
+
+	var manager = endly.NewManager()
+	context := manager.NewContext(toolbox.NewContext())
+	defer context.Close()
+	service, err := context.Service(endly.VersionControlServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var target = url.NewResource("file://" + path.Join(directory, "repo"))
+	target.Type = endly.VersionControlGoGitType
+	serviceResponse := service.Run(context, &endly.VcCommitRequest{
+		Target:  target,
+		Message: "initial import",
+		Author:  "endly",
+		Email:   "endly@viant.com",
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	info, ok := serviceResponse.Response.(*endly.VcInfo)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.True(t, info.IsUptoDate)
+
+	//pushed commit is in the bare origin repository with requested author
+	reference, err := origin.Reference(plumbing.NewBranchReferenceName("master"), true)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, info.Revision, reference.Hash().String())
+	commit, err := origin.CommitObject(reference.Hash())
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "initial import", commit.Message)
+		assert.EqualValues(t, "endly", commit.Author.Name)
+		assert.EqualValues(t, "endly@viant.com", commit.Author.Email)
+	}
+}
+
+func TestVc_GitCommit(t *testing.T) {
+	credentialFile, err := GetDummyCredential()
+	assert.Nil(t, err)
+	var target = url.NewResource("scp://127.0.0.1:22/tmp/myproj", credentialFile)
+	target.Type = "git"
+	var manager = endly.NewManager()
+	execService, err := GetReplayService("test/vc/git/commit/linux")
+	if !assert.Nil(t, err) {
+		return
+	}
+	context, err := OpenTestContext(manager, target, execService)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer context.Close()
+	service, err := context.Service(endly.VersionControlServiceID)
+	if !assert.Nil(t, err) {
+		return
+	}
+	serviceResponse := service.Run(context, &endly.VcCommitRequest{
+		Target:  target,
+		Message: `it's "fixed"`,
+		Author:  "O'Brien",
+		Email:   "obrien@viant.com",
+	})
+	if !assert.Equal(t, "", serviceResponse.Error) {
+		return
+	}
+	info, ok := serviceResponse.Response.(*endly.VcInfo)
+	if assert.True(t, ok) {
+		assert.Equal(t, "5d7c0a1e29dd36c32fdac1205cc12ce849812b9e", info.Revision)
+		assert.Equal(t, `it's "fixed"`, info.Message)
+	}
+	var executed = make([]string, 0)
+	for _, event := range context.Events.Events {
+		if executionEvent, ok := event.Value["value"].(*endly.ExecutionStartEvent); ok {
+			executed = append(executed, executionEvent.Stdin)
+		}
+	}
+	assert.Contains(t, executed, `git commit -m 'it'\''s "fixed"' -a --author='O'\''Brien <obrien@viant.com>'`)
+}
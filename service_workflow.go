@@ -380,12 +380,36 @@ func (s *workflowService) publishEvents(context *Context, events []*Event) {
 	}
 }
 
+func (s *workflowService) enableRecorderIfNeeded(context *Context, request *WorkflowRunRequest) error {
+	if request.RecordDirectory == "" && request.ReplayDirectory == "" {
+		return nil
+	}
+	if request.RecordDirectory != "" && request.ReplayDirectory != "" {
+		return errors.New("RecordDirectory and ReplayDirectory are mutually exclusive")
+	}
+	var recorder *Recorder
+	var err error
+	if request.RecordDirectory != "" {
+		recorder, err = NewRecorder(RecorderModeRecord, context.Expand(request.RecordDirectory))
+	} else {
+		recorder, err = NewRecorder(RecorderModeReplay, context.Expand(request.ReplayDirectory))
+	}
+	if err != nil {
+		return err
+	}
+	context.SetRecorder(recorder)
+	return nil
+}
+
 func (s *workflowService) runWorkflow(upstreamContext *Context, request *WorkflowRunRequest) (*WorkflowRunResponse, error) {
 	if request.EnableLogging {
 		upstreamContext.EventLogger = NewEventLogger(path.Join(request.LoggingDirectory, upstreamContext.SessionID))
 	}
-
-	var err = s.loadWorkflowIfNeeded(upstreamContext, request.Name, request.WorkflowURL)
+	var err = s.enableRecorderIfNeeded(upstreamContext, request)
+	if err != nil {
+		return nil, err
+	}
+	err = s.loadWorkflowIfNeeded(upstreamContext, request.Name, request.WorkflowURL)
 	if err != nil {
 		return nil, err
 	}
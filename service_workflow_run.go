@@ -10,6 +10,8 @@ type WorkflowRunRequest struct {
 	Tasks             string                 //tasks to run with coma separated list or '*', or empty string for all tasks
 	PublishParameters bool                   //publishes parameters Id into context state
 	Async             bool                   //flag to run it asynchronously. Do not set it yourself runner only sets the first workflow asyn
+	RecordDirectory   string                 //if specified, all exec commands, http trips, rest calls and transfers are captured into this directory
+	ReplayDirectory   string                 //if specified, exec commands, http trips, rest calls and transfers are replayed from this directory, no network or hosts are used
 }
 
 //WorkflowRunResponse represents workflow run response
@@ -1,6 +1,7 @@
 package endly
 
 import (
+	"fmt"
 	"github.com/lunixbochs/vtclean"
 	"strings"
 	"unicode"
@@ -74,3 +75,57 @@ func ExtractColumns(line string) ([]string, bool) {
 func escapeStdout(stdout string) string {
 	return vtclean.Clean(stdout, true)
 }
+
+//quoteShellArgument wraps value in single quotes, single quotes inside value are closed, escaped and reopened
+func quoteShellArgument(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+//splitShellArguments splits command line on unquoted whitespace, single and double quotes group words, backslash escapes next character outside single quotes
+func splitShellArguments(command string) ([]string, error) {
+	var result = make([]string, 0)
+	var word = make([]rune, 0)
+	var inWord, escaped bool
+	var quote rune
+	for _, r := range command {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				result = append(result, string(word))
+				word = word[:0]
+				inWord = false
+			}
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in: %v", command)
+	}
+	if inWord {
+		result = append(result, string(word))
+	}
+	return result, nil
+}
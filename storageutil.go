@@ -0,0 +1,30 @@
+package endly
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+)
+
+//downloadContent returns resource content
+func downloadContent(context *Context, resource *url.Resource) ([]byte, error) {
+	resource, err := context.ExpandResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	service, err := getStorageService(context, resource)
+	if err != nil {
+		return nil, err
+	}
+	defer service.Close()
+	object, err := service.StorageObject(resource.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %v, %v", resource.URL, err)
+	}
+	reader, err := service.Download(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %v, %v", resource.URL, err)
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
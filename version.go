@@ -0,0 +1,190 @@
+package endly
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var versionRangeExpr = regexp.MustCompile(`[\^~<>=|*\s]|(^|\.)[xX](\.|$)`)
+var versionComparatorExpr = regexp.MustCompile(`^(>=|<=|>|<|=|\^|~)?\s*v?(\S+)$`)
+var versionExpr = regexp.MustCompile(`\d+(\.\d+)*`)
+
+//versionComparator represents a single version comparison, only specified version fragments are compared
+type versionComparator struct {
+	operator string
+	version  []int
+}
+
+//versionConstraint represents alternatives (||) of comparator sets that all need to match
+type versionConstraint [][]*versionComparator
+
+//isVersionRange returns true if version uses range syntax, i.e. ^1.2, ~1.2.3, >=11 <12, 1.x
+func isVersionRange(version string) bool {
+	return versionRangeExpr.MatchString(strings.TrimSpace(version))
+}
+
+//parseVersion returns leading numeric version fragments, i.e. v1.8.0_151 returns [1 8 0], x or * ends the version
+func parseVersion(version string) []int {
+	var result = make([]int, 0)
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	for _, fragment := range strings.Split(version, ".") {
+		var digits = 0
+		for digits < len(fragment) && fragment[digits] >= '0' && fragment[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 {
+			break
+		}
+		var value = 0
+		for _, digit := range fragment[:digits] {
+			value = value*10 + int(digit-'0')
+		}
+		result = append(result, value)
+		if digits < len(fragment) {
+			break
+		}
+	}
+	return result
+}
+
+//compareVersionFragments compares the first count fragments, missing fragments are treated as zero
+func compareVersionFragments(actual, expected []int, count int) int {
+	for i := 0; i < count; i++ {
+		var actualFragment, expectedFragment int
+		if i < len(actual) {
+			actualFragment = actual[i]
+		}
+		if i < len(expected) {
+			expectedFragment = expected[i]
+		}
+		if actualFragment != expectedFragment {
+			if actualFragment < expectedFragment {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+//compareVersions compares versions or the lowest version of ranges, empty version is lower than any other one
+func compareVersions(version1, version2 string) int {
+	if version1 == "" || version2 == "" {
+		if version1 == version2 {
+			return 0
+		} else if version1 == "" {
+			return -1
+		}
+		return 1
+	}
+	var fragments1 = parseVersion(versionExpr.FindString(version1))
+	var fragments2 = parseVersion(versionExpr.FindString(version2))
+	var count = len(fragments1)
+	if len(fragments2) > count {
+		count = len(fragments2)
+	}
+	return compareVersionFragments(fragments1, fragments2, count)
+}
+
+func (c *versionComparator) matches(actual []int) bool {
+	if c.operator == "=" {
+		var count = len(c.version)
+		if len(actual) < count {
+			count = len(actual)
+		}
+		return compareVersionFragments(actual, c.version, count) == 0
+	}
+	var result = compareVersionFragments(actual, c.version, len(c.version))
+	switch c.operator {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	}
+	return false
+}
+
+func (c versionConstraint) matches(actual []int) bool {
+	for _, comparators := range c {
+		var matched = true
+		for _, comparator := range comparators {
+			if !comparator.matches(actual) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+//newVersionComparators returns comparators for a range expression, caret and tilde ranges are expanded into lower and upper bound
+func newVersionComparators(expression string) ([]*versionComparator, error) {
+	matched := versionComparatorExpr.FindStringSubmatch(expression)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("invalid version range: %v", expression)
+	}
+	var operator, version = matched[1], parseVersion(matched[2])
+	if len(version) == 0 {
+		if matched[2] == "*" || strings.ToLower(matched[2]) == "x" {
+			return []*versionComparator{}, nil
+		}
+		return nil, fmt.Errorf("invalid version: %v", expression)
+	}
+	switch operator {
+	case "", "=":
+		return []*versionComparator{{operator: "=", version: version}}, nil
+	case "^":
+		var index = len(version) - 1
+		for i, fragment := range version {
+			if fragment != 0 {
+				index = i
+				break
+			}
+		}
+		var upper = append([]int{}, version[:index+1]...)
+		upper[index]++
+		return []*versionComparator{{operator: ">=", version: version}, {operator: "<", version: upper}}, nil
+	case "~":
+		var upper = append([]int{}, version...)
+		if len(upper) > 2 {
+			upper = upper[:2]
+		}
+		upper[len(upper)-1]++
+		return []*versionComparator{{operator: ">=", version: version}, {operator: "<", version: upper}}, nil
+	}
+	return []*versionComparator{{operator: operator, version: version}}, nil
+}
+
+//parseVersionConstraint parses semantic version range, i.e. ^1.2, ~1.2.3, >=11 <12, 1.2 - 1.4, 1.x || 2.x
+func parseVersionConstraint(expression string) (versionConstraint, error) {
+	var result = make(versionConstraint, 0)
+	for _, alternative := range strings.Split(expression, "||") {
+		var fields = strings.Fields(alternative)
+		if len(fields) == 3 && fields[1] == "-" {
+			fields = []string{">=" + fields[0], "<=" + fields[2]}
+		}
+		var comparators = make([]*versionComparator, 0)
+		for i := 0; i < len(fields); i++ {
+			var field = fields[i]
+			if strings.Trim(field, "<>=^~") == "" && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			fieldComparators, err := newVersionComparators(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, fieldComparators...)
+		}
+		result = append(result, comparators)
+	}
+	return result, nil
+}